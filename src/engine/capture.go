@@ -0,0 +1,106 @@
+// VoV engine
+package engine
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/gen2brain/vov/src/system/home"
+	"github.com/gen2brain/vov/src/system/log"
+)
+
+// Captures current frame at logical size
+func (e *Engine) Capture() (*image.RGBA, error) {
+	if e.Renderer == nil {
+		return nil, errors.New("no renderer")
+	}
+
+	lw, lh := int(e.Cfg.WinWidth), int(e.Cfg.WinHeight)
+
+	ow, oh, err := e.Renderer.GetOutputSize()
+	if err != nil {
+		return nil, err
+	}
+
+	// Viewport is letterboxed in output, same as SetLogicalSize does
+	scale := float64(ow) / float64(lw)
+	if s := float64(oh) / float64(lh); s < scale {
+		scale = s
+	}
+
+	vw, vh := int(float64(lw)*scale), int(float64(lh)*scale)
+	if vw <= 0 || vh <= 0 {
+		return nil, errors.New("invalid output size")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, vw, vh))
+
+	// ABGR8888 is RGBA byte order on little endian
+	err = e.Renderer.ReadPixels(nil, sdl.PIXELFORMAT_ABGR8888, unsafe.Pointer(&img.Pix[0]), img.Stride)
+	if err != nil {
+		return nil, err
+	}
+
+	if vw == lw && vh == lh {
+		return img, nil
+	}
+
+	return scaleImage(img, lw, lh), nil
+}
+
+// Saves current frame as png to screenshots directory
+func (e *Engine) Screenshot() {
+	e.TakeScreenshot = false
+
+	img, err := e.Capture()
+	if err != nil {
+		log.Error("Capture: %s\n", err)
+		return
+	}
+
+	dir := filepath.Join(home.Dir(), ".vov", "screenshots")
+	if _, err := os.Stat(dir); err != nil {
+		os.MkdirAll(dir, 0755)
+	}
+
+	name := "vov-" + time.Now().Format("20060102-150405.000") + ".png"
+
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		log.Error("Create: %s\n", err)
+		return
+	}
+	defer f.Close()
+
+	err = png.Encode(f, img)
+	if err != nil {
+		log.Error("Encode: %s\n", err)
+	}
+}
+
+// Scales image to given size, nearest neighbour
+func scaleImage(src *image.RGBA, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+
+	for y := 0; y < height; y++ {
+		sy := y * sh / height
+		for x := 0; x < width; x++ {
+			sx := x * sw / width
+
+			i := src.PixOffset(sx, sy)
+			j := dst.PixOffset(x, y)
+			copy(dst.Pix[j:j+4], src.Pix[i:i+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,133 @@
+// VoV engine
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Source of milliseconds for clock
+type TimeSource interface {
+	Ticks() uint32
+}
+
+// Time source with SDL ticks
+type SDLTimeSource struct{}
+
+// Returns milliseconds since SDL initialization
+func (s SDLTimeSource) Ticks() uint32 {
+	return sdl.GetTicks()
+}
+
+// Time source advanced by hand
+type ManualTimeSource struct {
+	// Current time (milliseconds)
+	Now uint32
+}
+
+// Returns current time
+func (s *ManualTimeSource) Ticks() uint32 {
+	return s.Now
+}
+
+// Advances time by delta milliseconds
+func (s *ManualTimeSource) Advance(delta uint32) {
+	s.Now += delta
+}
+
+// Clock structure
+type Clock struct {
+	// Time source
+	Source TimeSource
+
+	// Paused boolean
+	Paused bool
+
+	// Started boolean
+	Started bool
+
+	// Was paused before entering background
+	WasPaused bool
+
+	// The time when the clock started
+	StartTicks uint32
+
+	// The ticks stored when the clock was paused
+	PausedTicks uint32
+}
+
+// Returns new clock
+func NewClock(source TimeSource) (c *Clock) {
+	c = &Clock{}
+	c.Source = source
+	return
+}
+
+// Starts clock
+func (c *Clock) Start() {
+	// Start the clock
+	c.Started = true
+
+	// Unpause the clock
+	c.Paused = false
+
+	// Get the current time
+	c.StartTicks = c.Source.Ticks()
+	c.PausedTicks = 0
+}
+
+// Stops clock
+func (c *Clock) Stop() {
+	// Stop the clock
+	c.Started = false
+
+	// Unpause the clock
+	c.Paused = false
+
+	// Clear tick variables
+	c.StartTicks = 0
+	c.PausedTicks = 0
+}
+
+// Pauses clock
+func (c *Clock) Pause() {
+	if c.Started && !c.Paused {
+		// Pause the clock
+		c.Paused = true
+
+		// Calculate the paused ticks
+		c.PausedTicks = c.Source.Ticks() - c.StartTicks
+		c.StartTicks = 0
+	}
+}
+
+// Unpauses clock
+func (c *Clock) Unpause() {
+	if c.Started && c.Paused {
+		// Unpause the clock
+		c.Paused = false
+
+		// Reset the starting ticks
+		c.StartTicks = c.Source.Ticks() - c.PausedTicks
+
+		// Reset the paused ticks
+		c.PausedTicks = 0
+	}
+}
+
+// Returns clock's time
+func (c *Clock) Ticks() uint32 {
+	// The actual clock time
+	var time uint32 = 0
+
+	if c.Started {
+		if c.Paused {
+			// The number of ticks when the clock was paused
+			time = c.PausedTicks
+		} else {
+			// The current time minus the start time
+			time = c.Source.Ticks() - c.StartTicks
+		}
+	}
+
+	return time
+}
@@ -0,0 +1,146 @@
+// VoV engine
+package engine
+
+import (
+	"testing"
+)
+
+func TestClock(t *testing.T) {
+	source := &ManualTimeSource{Now: 1000}
+	c := NewClock(source)
+
+	if c.Ticks() != 0 {
+		t.Errorf("stopped clock ticks %d, want 0", c.Ticks())
+	}
+
+	c.Start()
+	source.Advance(100)
+
+	if c.Ticks() != 100 {
+		t.Errorf("ticks %d, want 100", c.Ticks())
+	}
+
+	// Paused clock keeps time
+	c.Pause()
+	source.Advance(500)
+
+	if !c.Paused || c.Ticks() != 100 {
+		t.Errorf("paused ticks %d, want 100", c.Ticks())
+	}
+
+	// Time spent paused is skipped
+	c.Unpause()
+	source.Advance(50)
+
+	if c.Paused || c.Ticks() != 150 {
+		t.Errorf("unpaused ticks %d, want 150", c.Ticks())
+	}
+
+	c.Stop()
+
+	if c.Started || c.Ticks() != 0 {
+		t.Errorf("stopped ticks %d, want 0", c.Ticks())
+	}
+}
+
+func TestClockShared(t *testing.T) {
+	source := &ManualTimeSource{}
+
+	game := NewClock(source)
+	game.Start()
+
+	ui := NewClock(source)
+	ui.Start()
+
+	source.Advance(10)
+	game.Pause()
+	source.Advance(20)
+
+	if game.Ticks() != 10 {
+		t.Errorf("game ticks %d, want 10", game.Ticks())
+	}
+
+	if ui.Ticks() != 30 {
+		t.Errorf("ui ticks %d, want 30", ui.Ticks())
+	}
+}
+
+func TestHeadlessAdvance(t *testing.T) {
+	c := &Config{}
+	c.Default()
+
+	e := NewEngine(c)
+	if err := e.InitHeadless(640, 480); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		e.Advance(16)
+	}
+
+	if e.StepTicks != 160 || e.Steps != 10 {
+		t.Errorf("step ticks %d steps %d, want 160 10", e.StepTicks, e.Steps)
+	}
+
+	if e.Clock.Ticks() != 160 || e.UIClock.Ticks() != 160 {
+		t.Errorf("clock ticks %d ui %d, want 160", e.Clock.Ticks(), e.UIClock.Ticks())
+	}
+
+	// Game clock stays while paused, UI clock runs
+	e.Pause()
+	e.Advance(16)
+
+	if e.Clock.Ticks() != 160 || e.UIClock.Ticks() != 176 {
+		t.Errorf("paused clock ticks %d ui %d, want 160 176", e.Clock.Ticks(), e.UIClock.Ticks())
+	}
+
+	// Seeking doesn't move clocks or count steps
+	e.Simulate(16)
+
+	if e.StepTicks != 192 || e.Steps != 11 || e.UIClock.Ticks() != 176 {
+		t.Errorf("simulate step ticks %d steps %d ui %d, want 192 11 176", e.StepTicks, e.Steps, e.UIClock.Ticks())
+	}
+}
+
+// Time source moved by test, not advanced with simulation
+type frameSource struct {
+	now uint32
+}
+
+func (s *frameSource) Ticks() uint32 {
+	return s.now
+}
+
+func TestFrameDelta(t *testing.T) {
+	c := &Config{}
+	c.Default()
+	c.FixedStep = true
+	c.StepLength = 8
+
+	e := NewEngine(c)
+	if err := e.InitHeadless(640, 480); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &frameSource{}
+	e.Clock = NewClock(source)
+	e.Clock.Start()
+
+	steps := 0
+	for i := 0; i < 10; i++ {
+		e.StartFrame()
+		for e.Step() {
+			steps++
+		}
+
+		// Frame takes 2ms of work, sleep fills the rest
+		source.now += 2
+		e.EndFrame()
+		source.now += 14
+	}
+
+	// Sleep is simulated too, nine frame starts are 144ms apart
+	if steps != 18 || e.FrameDelta != 16 {
+		t.Errorf("steps %d frame delta %d, want 18 16", steps, e.FrameDelta)
+	}
+}
@@ -13,6 +13,28 @@ import (
 
 // Configuration structure
 type Config struct {
+	Preferences
+	Tuning
+	Runtime
+	Session
+
+	// Preferences overridden from command line or environment, by field name
+	Overrides map[string]Override
+	// Tuning overridden from command line or environment, by field name
+	TuningOverrides map[string]Override
+}
+
+// Config file, empty for default in home directory
+var ConfigFile string
+
+// Tuning profile, empty for default in home directory
+var TuningFile string
+
+// Player preferences, saved to config file
+type Preferences struct {
+	// Config schema version
+	Version int
+
 	// Enable music
 	MusicEnabled bool
 
@@ -22,21 +44,81 @@ type Config struct {
 	// Enable accelerometer
 	AccelerometerEnabled bool
 
-	// Accelerometer threshold
+	// Accelerometer deadzone, in axis units
 	AccelThreshold float64
 
+	// Accelerometer sensitivity, full thrust at 1/sensitivity of full tilt
+	AccelSensitivity float64
+
+	// Accelerometer smoothing, 0 is none and values near 1 are smooth but slow
+	AccelSmoothing float64
+
 	// Touch threshold
 	TouchThreshold float64
 
+	// Longest touch in milliseconds counted as tap
+	TapLength int
+
+	// Show virtual joystick for touch and mouse
+	VirtualStick bool
+
+	// Drag distance for full thrust, fraction of window height
+	DragRadius float64
+
+	// Controller stick deadzone, fraction of full deflection
+	StickDeadzone float64
+
+	// Controller stick response curve exponent, 1 is linear
+	StickCurve float64
+
 	// Enable haptic
 	HapticEnabled bool
 
 	// Show frames per second
 	ShowFps bool
 
+	// Show ghost of best run
+	GhostEnabled bool
+
+	// Transition between states, none, fade, crossfade or slide
+	Transition string
+
+	// Length of transition in milliseconds
+	TransitionLength int
+
 	// Maximum frames per second
 	MaxFps int
 
+	// Length of recorded gif in seconds
+	GifSeconds int
+
+	// Frames per second of recorded gif
+	GifFps int
+
+	// Width of recorded gif
+	GifWidth int
+
+	// Export gif automatically on game over
+	GifOnDeath bool
+
+	// Keyboard bindings, action name to key names
+	KeyBindings map[string][]string
+
+	// Controller bindings, action name to button names
+	ButtonBindings map[string][]string
+}
+
+// Gameplay tuning, loaded from tuning profile and never saved
+type Tuning struct {
+	// Simulate game in fixed steps, independent of frame rate
+	FixedStep bool
+
+	// Length of fixed simulation step in milliseconds
+	StepLength int
+
+	// Maximum number of simulation steps per frame
+	MaxSteps int
+
 	// Barrier speed
 	BarrierSpeed float64
 
@@ -134,6 +216,12 @@ type Config struct {
 	// Number of scores
 	NScores int
 
+	// Credits scroll speed
+	ScrollSpeed float64
+}
+
+// Runtime state, changed during game and never saved
+type Runtime struct {
 	// Window width
 	WinWidth float64
 
@@ -151,107 +239,265 @@ type Config struct {
 	// Maximum distance ahead
 	MaxDistAhead float64
 
-	// Credits scroll speed
-	ScrollSpeed float64
+	// Current game speed, lowered by slowdown powup
+	Speed float64
+
+	// Current engine dots of each thruster, raised by engine blast powup
+	ShipDots int
+}
+
+// Session settings from command line, kept for whole run and never saved
+type Session struct {
+	// Random seed of rocks and games, 0 for random
+	Seed int64
+
+	// Reload config and tuning files when they change
+	WatchConfig bool
 }
 
-// Returns new config
+// Returns new config, preferences and tuning from files are merged over defaults
 func NewConfig() (c *Config) {
 	c = &Config{}
-	if c.Exists() {
-		c.Load()
-	} else {
-		c.Default()
+	c.Default()
+
+	if c.Preferences.Exists() {
+		tuning, err := c.Preferences.Load()
+		if err != nil {
+			log.Error("Load: %s\n", err)
+		}
+
+		// Tuning values from old config become tuning profile
+		if _, ok := err.(*InvalidError); (err == nil || ok) && len(tuning) > 0 {
+			err = saveMigratedTuning(tuning)
+			if err != nil {
+				log.Error("WriteFile: %s\n", err)
+			}
+		}
 	}
+
+	if c.Tuning.Exists() {
+		err := c.Tuning.Load()
+		if err != nil {
+			log.Error("Load: %s\n", err)
+		}
+	}
+
+	c.ResetRuntime()
 	return
 }
 
 // Sets default config
 func (c *Config) Default() {
-	c.MusicEnabled = true
-	c.SoundsEnabled = true
-	c.AccelerometerEnabled = false
-	c.HapticEnabled = false
-	c.ShowFps = false
-	c.MaxFps = 60
-	c.AccelThreshold = 500
-	c.TouchThreshold = 0.05
-	c.BarrierSpeed = 7.5
-	c.Bounciness = 0.50
-	c.NRocks = 65
-	c.MaxRocks = 130
-	c.MaxPowups = 30
-	c.PowupsTimeout = 3000
-	c.PowupStateTimeout = 10000
-	c.PowupTextScale = 2.5
-	c.PowupTextTimeout = 1000
-	c.NFrames = 16
-	c.GameSpeed = 1.0
-	c.InitialRocks = 8
-	c.FinalRocks = 25
-	c.KH = 32 * 20
-	c.KV = 24 * 20
-	c.RDX = 2.5
-	c.RDY = 2.5
-	c.MaxDustDepth = 1
-	c.NDustMotes = 1500
-	c.NDustArray = 15
-	c.MaxShipDots = 1500
-	c.NShipDotsArray = 5
-	c.MaxBangDots = 1500
-	c.NBangDotsArray = 10
-	c.EngineDots = 1000
-	c.ThrusterStrength = 1.2
-	c.W = 100
-	c.M = 255
-	c.DotMassUnit = 0.07
-	c.DeadPauseLength = 40.0
-	c.GameOverLength = 250.0
-	c.ScoresLength = 10000.0
-	c.NScores = 8
-	c.InvinciblePauseLength = 2000.0
-	c.XScrollTo = c.WinWidth / 3
-	c.YScrollTo = c.WinHeight / 2
-	c.MaxDistAhead = c.WinWidth
-	c.ScrollSpeed = 0.8
+	c.Preferences.Default()
+	c.Tuning.Default()
+	c.ResetRuntime()
+}
+
+// Resets runtime values changed during game
+func (c *Config) ResetRuntime() {
+	c.DistAhead = 0
+	c.Speed = c.GameSpeed
+	c.ShipDots = c.EngineDots
+}
+
+// Sets default preferences
+func (p *Preferences) Default() {
+	p.Version = ConfigVersion
+	p.MusicEnabled = true
+	p.SoundsEnabled = true
+	p.AccelerometerEnabled = false
+	p.HapticEnabled = false
+	p.ShowFps = false
+	p.GhostEnabled = false
+	p.Transition = TRANSITION_FADE
+	p.TransitionLength = 400
+	p.MaxFps = 60
+	p.GifSeconds = 10
+	p.GifFps = 10
+	p.GifWidth = 320
+	p.GifOnDeath = false
+	p.KeyBindings = DefaultKeyBindings()
+	p.ButtonBindings = DefaultButtonBindings()
+	p.AccelThreshold = 500
+	p.AccelSensitivity = 4
+	p.AccelSmoothing = 0.7
+	p.TouchThreshold = 0.05
+	p.TapLength = 250
+	p.VirtualStick = false
+	p.DragRadius = 0.15
+	p.StickDeadzone = 0.2
+	p.StickCurve = 1.5
+}
+
+// Sets default tuning
+func (t *Tuning) Default() {
+	t.FixedStep = true
+	t.StepLength = 16
+	t.MaxSteps = 10
+	t.BarrierSpeed = 7.5
+	t.Bounciness = 0.50
+	t.NRocks = 65
+	t.MaxRocks = 130
+	t.MaxPowups = 30
+	t.PowupsTimeout = 3000
+	t.PowupStateTimeout = 10000
+	t.PowupTextScale = 2.5
+	t.PowupTextTimeout = 1000
+	t.NFrames = 16
+	t.GameSpeed = 1.0
+	t.InitialRocks = 8
+	t.FinalRocks = 25
+	t.KH = 32 * 20
+	t.KV = 24 * 20
+	t.RDX = 2.5
+	t.RDY = 2.5
+	t.MaxDustDepth = 1
+	t.NDustMotes = 1500
+	t.NDustArray = 15
+	t.MaxShipDots = 1500
+	t.NShipDotsArray = 5
+	t.MaxBangDots = 1500
+	t.NBangDotsArray = 10
+	t.EngineDots = 1000
+	t.ThrusterStrength = 1.2
+	t.W = 100
+	t.M = 255
+	t.DotMassUnit = 0.07
+	t.DeadPauseLength = 40.0
+	t.GameOverLength = 250.0
+	t.ScoresLength = 10000.0
+	t.NScores = 8
+	t.InvinciblePauseLength = 2000.0
+	t.ScrollSpeed = 0.8
+}
+
+// Loads preferences from file over current values, file is migrated to current version
+// and invalid values are reset to defaults. Returns tuning values moved out of old config
+func (p *Preferences) Load() (map[string]interface{}, error) {
+	js, err := ioutil.ReadFile(p.File())
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Decode(js)
 }
 
-// Loads config from file
-func (c *Config) Load() {
-	file := filepath.Join(home.Dir(), ".vov", "config")
-	js, err := ioutil.ReadFile(file)
+// Decodes preferences from json over current values, returns tuning values moved out of old config
+func (p *Preferences) Decode(js []byte) (tuning map[string]interface{}, err error) {
+	var m map[string]interface{}
+	err = json.Unmarshal(js, &m)
+	if err != nil {
+		return
+	}
+
+	tuning, err = Migrate(m)
 	if err != nil {
-		log.Error("ReadFile: %s\n", err)
+		return
 	}
 
-	err = json.Unmarshal(js, c)
+	js, err = json.Marshal(m)
 	if err != nil {
-		log.Error("Unmarshal: %s\n", err)
+		return
 	}
+
+	err = json.Unmarshal(js, p)
+	if err != nil {
+		return
+	}
+
+	err = p.Validate()
+	return
 }
 
-// Saves config to file
-func (c *Config) Save() {
-	dir := filepath.Join(home.Dir(), ".vov")
+// Saves preferences to file
+func (p *Preferences) Save() {
+	dir := filepath.Dir(p.File())
 	if _, err := os.Stat(dir); err != nil {
 		os.Mkdir(dir, 0755)
 	}
 
-	js, err := json.MarshalIndent(c, "", "    ")
+	p.Version = ConfigVersion
+
+	js, err := json.MarshalIndent(p, "", "    ")
 	if err != nil {
 		log.Error("Marshal: %s\n", err)
 	}
 
-	err = ioutil.WriteFile(filepath.Join(dir, "config"), js, 0644)
+	err = ioutil.WriteFile(p.File(), js, 0644)
 	if err != nil {
 		log.Error("WriteFile: %s\n", err)
 	}
 }
 
-// Checks if config file exists
-func (c *Config) Exists() bool {
-	file := filepath.Join(home.Dir(), ".vov", "config")
-	if _, err := os.Stat(file); err == nil {
+// Returns preferences file name
+func (p *Preferences) File() string {
+	if ConfigFile != "" {
+		return ConfigFile
+	}
+
+	return filepath.Join(home.Dir(), ".vov", "config")
+}
+
+// Checks if preferences file exists
+func (p *Preferences) Exists() bool {
+	if _, err := os.Stat(p.File()); err == nil {
+		return true
+	}
+	return false
+}
+
+// Loads tuning profile over current values, invalid values are reset to defaults
+func (t *Tuning) Load() error {
+	js, err := ioutil.ReadFile(t.File())
+	if err != nil {
+		return err
+	}
+
+	return t.Decode(js)
+}
+
+// Decodes tuning from json over current values
+func (t *Tuning) Decode(js []byte) error {
+	err := json.Unmarshal(js, t)
+	if err != nil {
+		return err
+	}
+
+	return t.Validate()
+}
+
+// Writes tuning values moved out of old config to tuning profile, existing profile is kept
+func saveMigratedTuning(values map[string]interface{}) error {
+	t := &Tuning{}
+	if t.Exists() {
+		return nil
+	}
+
+	dir := filepath.Dir(t.File())
+	if _, err := os.Stat(dir); err != nil {
+		os.Mkdir(dir, 0755)
+	}
+
+	js, err := json.MarshalIndent(values, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(t.File(), js, 0644)
+}
+
+// Returns tuning profile file name
+func (t *Tuning) File() string {
+	if TuningFile != "" {
+		return TuningFile
+	}
+
+	return filepath.Join(home.Dir(), ".vov", "tuning")
+}
+
+// Checks if tuning profile exists
+func (t *Tuning) Exists() bool {
+	if _, err := os.Stat(t.File()); err == nil {
 		return true
 	}
 	return false
@@ -0,0 +1,86 @@
+// VoV engine
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Points config and tuning files to temporary directory
+func tempConfigFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vov")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ConfigFile = filepath.Join(dir, "config")
+	TuningFile = filepath.Join(dir, "tuning")
+
+	t.Cleanup(func() {
+		ConfigFile, TuningFile = "", ""
+		os.RemoveAll(dir)
+	})
+}
+
+func TestMigrateTuningFile(t *testing.T) {
+	tempConfigFiles(t)
+
+	js := []byte(`{"Version": 2, "SoundsEnabled": false, "GameSpeed": 1.5, "NScores": 5, "ShipDots": 3}`)
+	if err := ioutil.WriteFile(ConfigFile, js, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConfig()
+
+	if c.SoundsEnabled || c.GameSpeed != 1.5 || c.NScores != 5 {
+		t.Errorf("sounds %v game speed %g scores %d, want false 1.5 5", c.SoundsEnabled, c.GameSpeed, c.NScores)
+	}
+
+	if !c.Tuning.Exists() {
+		t.Fatal("tuning profile not written")
+	}
+
+	// Existing profile is kept
+	if err := ioutil.WriteFile(TuningFile, []byte(`{"GameSpeed": 3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c = NewConfig()
+
+	if c.GameSpeed != 3 {
+		t.Errorf("game speed %g, want 3 from profile", c.GameSpeed)
+	}
+}
+
+func TestMigrateTuning(t *testing.T) {
+	m := map[string]interface{}{"Version": 2.0, "GameSpeed": 2.0, "Speed": 2.0}
+
+	tuning, err := Migrate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tuning) != 1 || tuning["GameSpeed"] != 2.0 {
+		t.Errorf("tuning %v, want GameSpeed 2", tuning)
+	}
+
+	// Decoding has no side effects
+	tempConfigFiles(t)
+
+	p := Preferences{}
+	p.Default()
+	tuning, err = p.Decode([]byte(`{"Version": 2, "GameSpeed": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tuning["GameSpeed"] != 2.0 || p.Version != ConfigVersion {
+		t.Errorf("decoded tuning %v version %d, want GameSpeed 2 version %d", tuning, p.Version, ConfigVersion)
+	}
+
+	if (&Tuning{}).Exists() {
+		t.Error("tuning profile written by decode")
+	}
+}
@@ -22,8 +22,11 @@ type Engine struct {
 
 	// SDL Window
 	Window *sdl.Window
-	// SDL Renderer
-	Renderer *sdl.Renderer
+	// Renderer
+	Renderer Renderer
+
+	// Input bindings
+	Input *Input
 
 	// Game controller
 	Controller *sdl.GameController
@@ -31,9 +34,24 @@ type Engine struct {
 	// Joystick
 	Joystick *sdl.Joystick
 
+	// Axis events of joystick with AccelerometerID come from accelerometer
+	Accelerometer   bool
+	AccelerometerID sdl.JoystickID
+
 	// Boolean set to true until exit
 	Running bool
 
+	// Game clock, paused with game
+	Clock *Clock
+	// UI clock, paused only in background
+	UIClock *Clock
+
+	// Headless mode, no window, renderer or audio
+	Headless bool
+
+	// Offscreen mode, renders to image without window or audio
+	Offscreen bool
+
 	// Screen X distance
 	ScreenDX float64
 	// Screen Y distance
@@ -50,10 +68,37 @@ type Engine struct {
 	StartTicks uint32
 	// End of frame (milliseconds)
 	EndTicks uint32
-	// Delta time between frames
+	// Delta time between frame starts
 	FrameDelta uint32
 	// Length of frame adjusted for gamespeed
 	TFrame float64
+
+	// Delta time of simulation step (milliseconds)
+	StepDelta uint32
+	// Simulation time (milliseconds)
+	StepTicks uint32
+	// Frame time not yet simulated (milliseconds)
+	Accumulator uint32
+	// Number of simulation steps in current frame
+	Steps int
+
+	// Save screenshot after next draw
+	TakeScreenshot bool
+
+	// Recorded frames for gif export
+	Clip *FrameBuffer
+
+	// Config file watcher, nil if disabled
+	Watcher *Watcher
+	// Number of tuning reloads that changed live values
+	TuningReloads int
+
+	// Message shown on screen, e.g. config errors
+	Message string
+	// Message is error
+	MessageError bool
+	// Message start (UI clock milliseconds)
+	MessageTicks uint32
 }
 
 // Returns new engine
@@ -62,7 +107,15 @@ func NewEngine(c *Config) (e *Engine) {
 	e.Cfg = c
 	e.Running = true
 
-	StartTimer()
+	e.State.Engine = e
+
+	e.Input = NewInput(c)
+
+	e.Clock = NewClock(SDLTimeSource{})
+	e.Clock.Start()
+
+	e.UIClock = NewClock(SDLTimeSource{})
+	e.UIClock.Start()
 
 	return
 }
@@ -100,8 +153,16 @@ func (e *Engine) Init() (err error) {
 		return
 	}
 
-	// Get window dimensions based on display aspect ratio
+	// Get window dimensions based on display aspect ratio, unless set in config
 	width, height := e.GetDimensions()
+	switch {
+	case e.Cfg.WinWidth > 0 && e.Cfg.WinHeight > 0:
+		width, height = int(e.Cfg.WinWidth), int(e.Cfg.WinHeight)
+	case e.Cfg.WinWidth > 0:
+		width, height = int(e.Cfg.WinWidth), int(e.Cfg.WinWidth*float64(height)/float64(width))
+	case e.Cfg.WinHeight > 0:
+		width, height = int(e.Cfg.WinHeight*float64(width)/float64(height)), int(e.Cfg.WinHeight)
+	}
 
 	// Create window
 	e.Window, err = sdl.CreateWindow("VoV", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width, height, sdl.WINDOW_SHOWN)
@@ -113,10 +174,11 @@ func (e *Engine) Init() (err error) {
 	e.UpdateDimensions()
 
 	// Create renderer
-	e.Renderer, err = sdl.CreateRenderer(e.Window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	renderer, err := NewSDLRenderer(e.Window)
 	if err != nil {
 		return
 	}
+	e.Renderer = renderer
 
 	// Set logical size
 	err = e.Renderer.SetLogicalSize(int(e.Cfg.WinWidth), int(e.Cfg.WinHeight))
@@ -127,21 +189,31 @@ func (e *Engine) Init() (err error) {
 	// Event filter callback
 	FilterEvent := func(event sdl.Event, userdata interface{}) bool {
 		switch t := event.(type) {
+		case *sdl.KeyDownEvent:
+			// Screenshot works in every state
+			if t.Keysym.Scancode == sdl.SCANCODE_F12 && t.Repeat == 0 {
+				e.TakeScreenshot = true
+			}
+
 		case *sdl.CommonEvent:
 			if t.Type == sdl.APP_WILLENTERBACKGROUND {
-				if Paused {
-					WasPaused = true
+				if e.Clock.Paused {
+					e.Clock.WasPaused = true
 				} else {
-					Pause()
+					e.Pause()
 				}
+
+				e.UIClock.Pause()
 			}
 
 			if t.Type == sdl.APP_WILLENTERFOREGROUND {
-				if WasPaused {
-					WasPaused = false
+				if e.Clock.WasPaused {
+					e.Clock.WasPaused = false
 				} else {
-					Unpause()
+					e.Unpause()
 				}
+
+				e.UIClock.Unpause()
 			}
 		}
 
@@ -161,6 +233,65 @@ func (e *Engine) Init() (err error) {
 	return
 }
 
+// Initializes engine without window, renderer or audio
+func (e *Engine) InitHeadless(width, height int) (err error) {
+	e.Headless = true
+
+	// There is no haptic device without SDL subsystems
+	e.Cfg.HapticEnabled = false
+
+	// SDL timer is not initialized, clocks are advanced with simulation
+	source := &ManualTimeSource{}
+
+	e.Clock = NewClock(source)
+	e.Clock.Start()
+
+	e.UIClock = NewClock(source)
+	e.UIClock.Start()
+
+	// Set dimensions
+	e.SetDimensions(width, height)
+
+	// Maximum FPS in milliseconds
+	e.FrameMs = uint32(1000 / e.Cfg.MaxFps)
+
+	return
+}
+
+// Initializes engine with in-memory image renderer, without window or audio
+func (e *Engine) InitOffscreen(width, height int) (err error) {
+	e.Offscreen = true
+
+	// There is no haptic device without SDL subsystems
+	e.Cfg.HapticEnabled = false
+
+	// Initialize ttf
+	err = ttf.Init()
+	if err != nil {
+		return
+	}
+
+	// Set dimensions
+	e.SetDimensions(width, height)
+
+	// Create renderer
+	renderer, err := NewImageRenderer(width, height)
+	if err != nil {
+		return
+	}
+	e.Renderer = renderer
+
+	// Maximum FPS in milliseconds
+	e.FrameMs = uint32(1000 / e.Cfg.MaxFps)
+
+	return
+}
+
+// Checks if audio is available
+func (e *Engine) Audio() bool {
+	return !e.Headless && !e.Offscreen
+}
+
 // Sets window icon
 func (e *Engine) SetIcon(icon *sdl.Surface) {
 	e.Window.SetIcon(icon)
@@ -209,6 +340,11 @@ func (e *Engine) GetDimensions() (width, height int) {
 // Updates window dimensions
 func (e *Engine) UpdateDimensions() {
 	w, h := e.Window.GetSize()
+	e.SetDimensions(w, h)
+}
+
+// Sets dimensions
+func (e *Engine) SetDimensions(w, h int) {
 	e.Cfg.WinWidth, e.Cfg.WinHeight = float64(w), float64(h)
 
 	e.Cfg.XScrollTo = e.Cfg.WinWidth / 3
@@ -219,36 +355,125 @@ func (e *Engine) UpdateDimensions() {
 
 // Calculates start frame
 func (e *Engine) StartFrame() {
-	// Get start ticks
-	e.StartTicks = GetTicks()
+	// Get start ticks, delta is real time since previous frame start including sleep
+	ticks := e.Clock.Ticks()
+	e.FrameDelta = 0
+	if ticks > e.StartTicks {
+		e.FrameDelta = ticks - e.StartTicks
+	}
+	e.StartTicks = ticks
 
-	// All movements are based on TFrame (1/20th of a second)
-	e.TFrame = e.Cfg.GameSpeed * float64(e.FrameDelta) / 50
+	e.Steps = 0
+
+	if e.FixedStep() {
+		// Accumulate frame time, drop what can't be simulated in one frame
+		e.Accumulator += e.FrameDelta
+		if max := uint32(e.Cfg.MaxSteps * e.Cfg.StepLength); e.Cfg.MaxSteps > 0 && e.Accumulator > max {
+			e.Accumulator = max
+		}
+	}
+}
+
+// Pauses game clock and music
+func (e *Engine) Pause() {
+	if e.Clock.Paused {
+		return
+	}
+
+	e.Clock.Pause()
+
+	if e.Audio() {
+		mix.PauseMusic()
+	}
+}
+
+// Unpauses game clock and music
+func (e *Engine) Unpause() {
+	if !e.Clock.Paused {
+		return
+	}
+
+	e.Clock.Unpause()
+
+	if e.Audio() {
+		mix.ResumeMusic()
+	}
+}
+
+// Checks if simulation runs in fixed steps
+func (e *Engine) FixedStep() bool {
+	return e.Cfg.FixedStep && e.Cfg.StepLength > 0
+}
+
+// Advances simulation by one step, returns false when there are no more steps in frame
+func (e *Engine) Step() bool {
+	if !e.FixedStep() {
+		// Variable step, one update per frame
+		if e.Steps > 0 {
+			return false
+		}
+
+		e.Advance(e.FrameDelta)
+		return true
+	}
+
+	step := uint32(e.Cfg.StepLength)
+	if e.Accumulator < step {
+		return false
+	}
+
+	e.Accumulator -= step
+	e.Advance(step)
+
+	return true
 }
 
 // Calculates end frame
 func (e *Engine) EndFrame() {
-	if !Paused {
+	if !e.Clock.Paused {
 		// Increment frame counter
 		e.Frames++
 	}
 
-	// Get end ticks and calculate delta
-	e.EndTicks = GetTicks()
-	e.FrameDelta = e.EndTicks - e.StartTicks
+	// Get end ticks
+	e.EndTicks = e.Clock.Ticks()
 
 	// Cap the frame rate
-	if e.FrameMs > e.FrameDelta {
+	if work := e.EndTicks - e.StartTicks; e.FrameMs > work {
 		// Sleep the remaining frame time
-		sdl.Delay(e.FrameMs - e.FrameDelta)
+		sdl.Delay(e.FrameMs - work)
 	}
 
 	// Calculate FPS
 	e.Fps = float64(e.Frames) / (float64(e.EndTicks) / 1000)
 }
 
+// Advances simulation by delta milliseconds, also used when there is no main loop
+func (e *Engine) Advance(delta uint32) {
+	e.Steps++
+	e.Simulate(delta)
+
+	// Without main loop time passes only with simulation
+	if source, ok := e.Clock.Source.(*ManualTimeSource); ok {
+		source.Advance(delta)
+	}
+}
+
+// Sets simulation step of delta milliseconds without counting it in frame, used for seeking
+func (e *Engine) Simulate(delta uint32) {
+	e.StepDelta = delta
+	e.StepTicks += delta
+
+	// All movements are based on TFrame (1/20th of a second)
+	e.TFrame = e.Cfg.Speed * float64(e.StepDelta) / 50
+}
+
 // Clears screen
 func (e *Engine) Clear() {
+	if e.Renderer == nil {
+		return
+	}
+
 	e.Renderer.Clear()
 	e.Renderer.SetDrawColor(0, 0, 0, 255)
 	e.Renderer.FillRect(nil)
@@ -300,9 +525,12 @@ func (e *Engine) SetAccelerometer() {
 		e.Joystick = sdl.JoystickOpen(sdl.JoystickID(i))
 
 		if e.Joystick.Name() == "Android Accelerometer" || e.Joystick.NumAxes() == 3 {
+			e.Accelerometer = true
+			e.AccelerometerID = e.Joystick.InstanceID()
 			break
 		} else {
 			e.Joystick.Close()
+			e.Joystick = nil
 		}
 	}
 }
@@ -322,6 +550,16 @@ func (e *Engine) SetHaptic() {
 
 // Destroys SDL and releases the memory
 func (e *Engine) Destroy() {
+	if e.Headless {
+		return
+	}
+
+	if e.Offscreen {
+		e.Renderer.Destroy()
+		ttf.Quit()
+		return
+	}
+
 	e.Renderer.Destroy()
 	e.Window.Destroy()
 
@@ -342,5 +580,6 @@ func (e *Engine) Destroy() {
 // Quits game loop
 func (e *Engine) Quit() {
 	e.Running = false
-	StopTimer()
+	e.Clock.Stop()
+	e.UIClock.Stop()
 }
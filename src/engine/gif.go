@@ -0,0 +1,191 @@
+// VoV engine
+package engine
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gen2brain/vov/src/system/home"
+	"github.com/gen2brain/vov/src/system/log"
+)
+
+// Palette with 3 bits of red and green and 2 bits of blue, fast to quantize
+var palette332 color.Palette
+
+func init() {
+	palette332 = make(color.Palette, 256)
+	for i := 0; i < 256; i++ {
+		r := uint8(i>>5) * 255 / 7
+		g := uint8(i>>2&7) * 255 / 7
+		b := uint8(i&3) * 255 / 3
+		palette332[i] = color.RGBA{r, g, b, 255}
+	}
+}
+
+// Ring buffer of downscaled frames
+type FrameBuffer struct {
+	Frames []*image.Paletted
+
+	// Next frame position
+	Pos int
+	// Number of frames in buffer
+	Count int
+
+	// Frame dimensions
+	Width  int
+	Height int
+
+	// Milliseconds between frames
+	Interval uint32
+	// Ticks of last frame
+	Last uint32
+
+	// Frames are recorded only when active
+	Active bool
+}
+
+// Returns new frame buffer
+func NewFrameBuffer(size int, interval uint32, width, height int) (f *FrameBuffer) {
+	f = &FrameBuffer{}
+	f.Frames = make([]*image.Paletted, size)
+	f.Interval = interval
+	f.Width = width
+	f.Height = height
+	return
+}
+
+// Adds frame, oldest frame is dropped when buffer is full
+func (f *FrameBuffer) Add(img *image.Paletted) {
+	f.Frames[f.Pos] = img
+	f.Pos = (f.Pos + 1) % len(f.Frames)
+
+	if f.Count < len(f.Frames) {
+		f.Count++
+	}
+}
+
+// Returns frames from oldest to newest
+func (f *FrameBuffer) Snapshot() []*image.Paletted {
+	frames := make([]*image.Paletted, 0, f.Count)
+
+	start := (f.Pos - f.Count + len(f.Frames)) % len(f.Frames)
+	for i := 0; i < f.Count; i++ {
+		frames = append(frames, f.Frames[(start+i)%len(f.Frames)])
+	}
+
+	return frames
+}
+
+// Removes all frames
+func (f *FrameBuffer) Reset() {
+	for i := range f.Frames {
+		f.Frames[i] = nil
+	}
+
+	f.Pos = 0
+	f.Count = 0
+	f.Last = 0
+}
+
+// Starts recording frames
+func (e *Engine) StartRecording() {
+	if e.Renderer == nil || e.Cfg.GifSeconds <= 0 || e.Cfg.GifFps <= 0 || e.Cfg.GifWidth <= 0 {
+		return
+	}
+
+	width := e.Cfg.GifWidth
+	height := int(float64(width) * e.Cfg.WinHeight / e.Cfg.WinWidth)
+
+	e.Clip = NewFrameBuffer(e.Cfg.GifSeconds*e.Cfg.GifFps, uint32(1000/e.Cfg.GifFps), width, height)
+	e.Clip.Active = true
+}
+
+// Stops recording frames
+func (e *Engine) StopRecording() {
+	e.Clip = nil
+}
+
+// Records current frame if interval passed
+func (e *Engine) RecordFrame() {
+	if e.Clip == nil || !e.Clip.Active || e.Clock.Paused {
+		return
+	}
+
+	if e.Clip.Count > 0 && e.StartTicks-e.Clip.Last < e.Clip.Interval {
+		return
+	}
+
+	img, err := e.Capture()
+	if err != nil {
+		log.Error("Capture: %s\n", err)
+		return
+	}
+
+	e.Clip.Add(quantizeImage(img, e.Clip.Width, e.Clip.Height))
+	e.Clip.Last = e.StartTicks
+}
+
+// Exports recorded frames as animated gif to gifs directory, encoding is done in background
+func (e *Engine) ExportGif() error {
+	if e.Clip == nil || e.Clip.Count == 0 {
+		return errors.New("no frames recorded")
+	}
+
+	frames := e.Clip.Snapshot()
+	delay := int(e.Clip.Interval / 10)
+
+	go func() {
+		g := &gif.GIF{}
+		for _, frame := range frames {
+			g.Image = append(g.Image, frame)
+			g.Delay = append(g.Delay, delay)
+		}
+
+		dir := filepath.Join(home.Dir(), ".vov", "gifs")
+		if _, err := os.Stat(dir); err != nil {
+			os.MkdirAll(dir, 0755)
+		}
+
+		name := "vov-" + time.Now().Format("20060102-150405.000") + ".gif"
+
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			log.Error("Create: %s\n", err)
+			return
+		}
+		defer f.Close()
+
+		err = gif.EncodeAll(f, g)
+		if err != nil {
+			log.Error("EncodeAll: %s\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// Scales image to given size and quantizes it to 332 palette, nearest neighbour
+func quantizeImage(src *image.RGBA, width, height int) *image.Paletted {
+	dst := image.NewPaletted(image.Rect(0, 0, width, height), palette332)
+
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+
+	for y := 0; y < height; y++ {
+		sy := y * sh / height
+		for x := 0; x < width; x++ {
+			sx := x * sw / width
+
+			i := src.PixOffset(sx, sy)
+			r, g, b := src.Pix[i], src.Pix[i+1], src.Pix[i+2]
+
+			dst.Pix[dst.PixOffset(x, y)] = r&0xe0 | (g&0xe0)>>3 | b>>6
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,341 @@
+// VoV engine
+package engine
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Input action
+type Action uint32
+
+// Input actions
+const (
+	ACTION_THRUST_LEFT Action = 1 << iota
+	ACTION_THRUST_RIGHT
+	ACTION_THRUST_UP
+	ACTION_THRUST_DOWN
+	ACTION_MENU_UP
+	ACTION_MENU_DOWN
+	ACTION_CONFIRM
+	ACTION_BACK
+	ACTION_PAUSE
+	ACTION_TOGGLE_FULLSCREEN
+	ACTION_EXPORT_GIF
+)
+
+// All actions, in order shown to player
+var Actions = []Action{
+	ACTION_THRUST_LEFT,
+	ACTION_THRUST_RIGHT,
+	ACTION_THRUST_UP,
+	ACTION_THRUST_DOWN,
+	ACTION_MENU_UP,
+	ACTION_MENU_DOWN,
+	ACTION_CONFIRM,
+	ACTION_BACK,
+	ACTION_PAUSE,
+	ACTION_TOGGLE_FULLSCREEN,
+	ACTION_EXPORT_GIF,
+}
+
+// Action names used in config
+var actionNames = map[Action]string{
+	ACTION_THRUST_LEFT:       "ThrustLeft",
+	ACTION_THRUST_RIGHT:      "ThrustRight",
+	ACTION_THRUST_UP:         "ThrustUp",
+	ACTION_THRUST_DOWN:       "ThrustDown",
+	ACTION_MENU_UP:           "MenuUp",
+	ACTION_MENU_DOWN:         "MenuDown",
+	ACTION_CONFIRM:           "Confirm",
+	ACTION_BACK:              "Back",
+	ACTION_PAUSE:             "Pause",
+	ACTION_TOGGLE_FULLSCREEN: "ToggleFullscreen",
+	ACTION_EXPORT_GIF:        "ExportGif",
+}
+
+// Returns action name
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// Returns default keyboard bindings, action name to key names
+func DefaultKeyBindings() map[string][]string {
+	return map[string][]string{
+		"ThrustLeft":       {"Left"},
+		"ThrustRight":      {"Right"},
+		"ThrustUp":         {"Up"},
+		"ThrustDown":       {"Down"},
+		"MenuUp":           {"Up"},
+		"MenuDown":         {"Down"},
+		"Confirm":          {"Return"},
+		"Back":             {"Escape", "AC Back"},
+		"Pause":            {"P", "Pause", "Space"},
+		"ToggleFullscreen": {"F11"},
+		"ExportGif":        {"G"},
+	}
+}
+
+// Returns default controller bindings, action name to button names
+func DefaultButtonBindings() map[string][]string {
+	return map[string][]string{
+		"ThrustLeft":       {"dpleft"},
+		"ThrustRight":      {"dpright"},
+		"ThrustUp":         {"dpup"},
+		"ThrustDown":       {"dpdown"},
+		"MenuUp":           {"dpup"},
+		"MenuDown":         {"dpdown"},
+		"Confirm":          {"a"},
+		"Back":             {"b", "back"},
+		"Pause":            {"start"},
+		"ToggleFullscreen": {},
+		"ExportGif":        {"y"},
+	}
+}
+
+// Input structure, maps raw events to actions
+type Input struct {
+	// Actions bound to keys
+	Keys map[sdl.Scancode]Action
+	// Actions bound to controller buttons
+	Buttons map[int]Action
+	// First bound key name of each action, for hints
+	KeyNames map[Action]string
+
+	// Stick deadzone and response curve
+	Deadzone float64
+	Curve    float64
+}
+
+// Returns new input with bindings from config
+func NewInput(c *Config) (i *Input) {
+	i = &Input{}
+	i.Load(c)
+	return
+}
+
+// Loads bindings from config, missing actions use defaults
+func (i *Input) Load(c *Config) {
+	i.Keys = make(map[sdl.Scancode]Action)
+	i.Buttons = make(map[int]Action)
+	i.KeyNames = make(map[Action]string)
+
+	i.Deadzone = c.StickDeadzone
+	i.Curve = c.StickCurve
+
+	keys := DefaultKeyBindings()
+	for name, k := range c.KeyBindings {
+		keys[name] = k
+	}
+
+	buttons := DefaultButtonBindings()
+	for name, b := range c.ButtonBindings {
+		buttons[name] = b
+	}
+
+	for _, a := range Actions {
+		for _, name := range keys[a.String()] {
+			if code := sdl.GetScancodeFromName(name); code != sdl.SCANCODE_UNKNOWN {
+				i.Keys[code] |= a
+
+				if i.KeyNames[a] == "" {
+					i.KeyNames[a] = name
+				}
+			}
+		}
+
+		for _, name := range buttons[a.String()] {
+			if button := sdl.GameControllerGetButtonFromString(name); button != sdl.CONTROLLER_BUTTON_INVALID {
+				i.Buttons[button] |= a
+			}
+		}
+	}
+}
+
+// Returns actions of event and whether they are pressed or released
+func (i *Input) Actions(event sdl.Event) (a Action, pressed bool) {
+	switch t := event.(type) {
+	case *sdl.KeyDownEvent:
+		// Alt+Enter is always fullscreen
+		if t.Keysym.Mod&sdl.KMOD_ALT != 0 && t.Keysym.Scancode == sdl.SCANCODE_RETURN {
+			return ACTION_TOGGLE_FULLSCREEN, true
+		}
+
+		return i.Keys[t.Keysym.Scancode], true
+
+	case *sdl.KeyUpEvent:
+		return i.Keys[t.Keysym.Scancode], false
+
+	case *sdl.ControllerButtonEvent:
+		return i.Buttons[int(t.Button)], t.Type == sdl.CONTROLLERBUTTONDOWN
+
+	case *sdl.ControllerAxisEvent:
+		// Left stick moves ship and menu selection
+		switch t.Axis {
+		case sdl.CONTROLLER_AXIS_LEFTX:
+			if t.Value < 0 {
+				return ACTION_THRUST_LEFT, true
+			} else if t.Value > 0 {
+				return ACTION_THRUST_RIGHT, true
+			}
+
+			return ACTION_THRUST_LEFT | ACTION_THRUST_RIGHT, false
+
+		case sdl.CONTROLLER_AXIS_LEFTY:
+			if t.Value < 0 {
+				return ACTION_THRUST_UP | ACTION_MENU_UP, true
+			} else if t.Value > 0 {
+				return ACTION_THRUST_DOWN | ACTION_MENU_DOWN, true
+			}
+
+			return ACTION_THRUST_UP | ACTION_THRUST_DOWN | ACTION_MENU_UP | ACTION_MENU_DOWN, false
+		}
+	}
+
+	return
+}
+
+// Returns stick position in range -1..1 with radial deadzone and response curve applied
+func (i *Input) Stick(xv, yv int16) (x, y float64) {
+	x = math.Max(float64(xv)/32767, -1)
+	y = math.Max(float64(yv)/32767, -1)
+
+	m := math.Hypot(x, y)
+	if m <= i.Deadzone || i.Deadzone >= 1 {
+		return 0, 0
+	}
+
+	r := math.Min((m-i.Deadzone)/(1-i.Deadzone), 1)
+	if i.Curve > 0 {
+		r = math.Pow(r, i.Curve)
+	}
+
+	return x / m * r, y / m * r
+}
+
+// Actions used only in game, they can share bindings with menu actions
+const gameActions = ACTION_THRUST_LEFT | ACTION_THRUST_RIGHT | ACTION_THRUST_UP | ACTION_THRUST_DOWN | ACTION_PAUSE | ACTION_EXPORT_GIF
+
+// Actions used only in menus
+const menuActions = ACTION_MENU_UP | ACTION_MENU_DOWN
+
+// Checks if actions can't share binding
+func Conflict(a, b Action) bool {
+	if a == b {
+		return false
+	}
+
+	if (a&gameActions != 0 && b&menuActions != 0) || (a&menuActions != 0 && b&gameActions != 0) {
+		return false
+	}
+
+	return true
+}
+
+// Returns actions that share binding with action and are used at the same time
+func Conflicts(bindings map[string][]string, a Action) (actions []Action) {
+	for _, b := range Actions {
+		if !Conflict(a, b) {
+			continue
+		}
+
+	loop:
+		for _, x := range bindings[a.String()] {
+			for _, y := range bindings[b.String()] {
+				if x == y {
+					actions = append(actions, b)
+					break loop
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// Binds name as first binding of action, other bindings of action are kept.
+// Conflicting actions get replaced binding instead, returns them
+func Rebind(bindings map[string][]string, a Action, name string) (swapped []Action) {
+	old := ""
+	if names := bindings[a.String()]; len(names) > 0 {
+		old = names[0]
+	}
+
+	if old == name {
+		return
+	}
+
+	for _, b := range Actions {
+		if !Conflict(a, b) {
+			continue
+		}
+
+		names := bindings[b.String()]
+		for i, x := range names {
+			if x != name {
+				continue
+			}
+
+			if old != "" && !contains(names, old) {
+				names[i] = old
+			} else {
+				names = append(names[:i:i], names[i+1:]...)
+			}
+
+			bindings[b.String()] = names
+			swapped = append(swapped, b)
+			break
+		}
+	}
+
+	names := []string{name}
+	for i, x := range bindings[a.String()] {
+		if i > 0 && x != name {
+			names = append(names, x)
+		}
+	}
+	bindings[a.String()] = names
+
+	return
+}
+
+// Checks if list contains name
+func contains(names []string, name string) bool {
+	for _, x := range names {
+		if x == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Adds default bindings for actions missing in preferences
+func (p *Preferences) MergeBindings() {
+	if p.KeyBindings == nil {
+		p.KeyBindings = make(map[string][]string)
+	}
+
+	if p.ButtonBindings == nil {
+		p.ButtonBindings = make(map[string][]string)
+	}
+
+	for name, keys := range DefaultKeyBindings() {
+		if _, ok := p.KeyBindings[name]; !ok {
+			p.KeyBindings[name] = keys
+		}
+	}
+
+	for name, buttons := range DefaultButtonBindings() {
+		if _, ok := p.ButtonBindings[name]; !ok {
+			p.ButtonBindings[name] = buttons
+		}
+	}
+}
+
+// Restores default bindings
+func (p *Preferences) ResetBindings() {
+	p.KeyBindings = DefaultKeyBindings()
+	p.ButtonBindings = DefaultButtonBindings()
+}
@@ -0,0 +1,128 @@
+// VoV engine
+package engine
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConflicts(t *testing.T) {
+	bindings := DefaultKeyBindings()
+
+	// Defaults share keys only between game and menu actions
+	for _, a := range Actions {
+		if c := Conflicts(bindings, a); len(c) != 0 {
+			t.Errorf("default %s conflicts with %v", a, c)
+		}
+	}
+
+	bindings["ExportGif"] = []string{"Return"}
+
+	if c := Conflicts(bindings, ACTION_CONFIRM); !reflect.DeepEqual(c, []Action{ACTION_EXPORT_GIF}) {
+		t.Errorf("Confirm conflicts %v, want ExportGif", c)
+	}
+
+	// Thrust and menu keys are used in different states
+	bindings["ThrustUp"] = []string{"Down"}
+
+	if c := Conflicts(bindings, ACTION_THRUST_UP); !reflect.DeepEqual(c, []Action{ACTION_THRUST_DOWN}) {
+		t.Errorf("ThrustUp conflicts %v, want ThrustDown", c)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	bindings := DefaultKeyBindings()
+
+	// Other bindings of action are kept
+	swapped := Rebind(bindings, ACTION_BACK, "Backspace")
+
+	if len(swapped) != 0 {
+		t.Errorf("swapped %v, want none", swapped)
+	}
+
+	if want := []string{"Backspace", "AC Back"}; !reflect.DeepEqual(bindings["Back"], want) {
+		t.Errorf("Back %v, want %v", bindings["Back"], want)
+	}
+
+	// Conflicting action gets replaced binding
+	swapped = Rebind(bindings, ACTION_CONFIRM, "Space")
+
+	if !reflect.DeepEqual(swapped, []Action{ACTION_PAUSE}) {
+		t.Errorf("swapped %v, want Pause", swapped)
+	}
+
+	if want := []string{"Space"}; !reflect.DeepEqual(bindings["Confirm"], want) {
+		t.Errorf("Confirm %v, want %v", bindings["Confirm"], want)
+	}
+
+	if want := []string{"P", "Pause", "Return"}; !reflect.DeepEqual(bindings["Pause"], want) {
+		t.Errorf("Pause %v, want %v", bindings["Pause"], want)
+	}
+
+	// Escape can be bound
+	Rebind(bindings, ACTION_EXPORT_GIF, "Escape")
+
+	if want := []string{"Escape"}; !reflect.DeepEqual(bindings["ExportGif"], want) {
+		t.Errorf("ExportGif %v, want %v", bindings["ExportGif"], want)
+	}
+
+	for _, a := range Actions {
+		if c := Conflicts(bindings, a); len(c) != 0 {
+			t.Errorf("%s conflicts with %v after rebind", a, c)
+		}
+	}
+}
+
+func TestKeyNames(t *testing.T) {
+	c := &Config{}
+	c.Default()
+
+	i := NewInput(c)
+	if name := i.KeyNames[ACTION_EXPORT_GIF]; name != "G" {
+		t.Errorf("ExportGif key %q, want G", name)
+	}
+
+	// Hint follows rebind
+	Rebind(c.KeyBindings, ACTION_EXPORT_GIF, "F5")
+	i.Load(c)
+
+	if name := i.KeyNames[ACTION_EXPORT_GIF]; name != "F5" {
+		t.Errorf("ExportGif key %q, want F5", name)
+	}
+}
+
+func TestStick(t *testing.T) {
+	i := &Input{Deadzone: 0.2, Curve: 2}
+
+	tests := []struct {
+		xv, yv int16
+		x, y   float64
+	}{
+		{0, 0, 0, 0},
+		// Inside radial deadzone
+		{6000, 0, 0, 0},
+		{4000, -4000, 0, 0},
+		// Full deflection
+		{32767, 0, 1, 0},
+		{-32768, 0, -1, 0},
+		{0, -32768, 0, -1},
+		// Halfway past deadzone, curved
+		{19660, 0, 0.25, 0},
+		// Corners are clamped to unit circle
+		{32767, 32767, math.Sqrt2 / 2, math.Sqrt2 / 2},
+	}
+
+	for _, tt := range tests {
+		x, y := i.Stick(tt.xv, tt.yv)
+		if math.Abs(x-tt.x) > 1e-3 || math.Abs(y-tt.y) > 1e-3 {
+			t.Errorf("stick %d,%d = %g,%g, want %g,%g", tt.xv, tt.yv, x, y, tt.x, tt.y)
+		}
+	}
+
+	// Linear without curve
+	i.Curve = 0
+	if x, _ := i.Stick(19660, 0); math.Abs(x-0.5) > 1e-3 {
+		t.Errorf("linear stick %g, want 0.5", x)
+	}
+}
@@ -0,0 +1,218 @@
+// VoV engine
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Current config schema version, files without version are version 1
+const ConfigVersion = 3
+
+// Migration of decoded config from version to next version, values that moved out of preferences are put in tuning
+type Migration func(m, tuning map[string]interface{})
+
+// Migrations, indexed by version they migrate from
+var Migrations = map[int]Migration{
+	1: migrate1,
+	2: migrate2,
+}
+
+// Version 1 saved window dependent values, they are computed on init
+func migrate1(m, tuning map[string]interface{}) {
+	for _, key := range []string{"WinWidth", "WinHeight", "XScrollTo", "YScrollTo", "DistAhead", "MaxDistAhead"} {
+		delete(m, key)
+	}
+
+	// Bindings were saved as null before they were set
+	for _, key := range []string{"KeyBindings", "ButtonBindings"} {
+		if v, ok := m[key]; ok && v == nil {
+			delete(m, key)
+		}
+	}
+}
+
+// Version 2 saved tuning and runtime values, they are not preferences
+func migrate2(m, tuning map[string]interface{}) {
+	typ := reflect.TypeOf(Tuning{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if v, ok := m[name]; ok {
+			tuning[name] = v
+			delete(m, name)
+		}
+	}
+
+	typ = reflect.TypeOf(Runtime{})
+	for i := 0; i < typ.NumField(); i++ {
+		delete(m, typ.Field(i).Name)
+	}
+}
+
+// Migrates decoded config to current version, returns tuning values moved out of config
+func Migrate(m map[string]interface{}) (tuning map[string]interface{}, err error) {
+	version := 1
+	if v, ok := m["Version"].(float64); ok {
+		version = int(v)
+	}
+
+	if version > ConfigVersion {
+		return nil, fmt.Errorf("unsupported config version %d", version)
+	}
+
+	tuning = make(map[string]interface{})
+
+	for ; version < ConfigVersion; version++ {
+		migrate, ok := Migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from config version %d", version)
+		}
+
+		migrate(m, tuning)
+	}
+
+	m["Version"] = ConfigVersion
+
+	return tuning, nil
+}
+
+// Invalid values found by validation
+type invalidList []string
+
+// Adds invalid value message
+func (l *invalidList) add(format string, a ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, a...))
+}
+
+// Returns error listing invalid values, nil if there are none
+func (l invalidList) err(what string) error {
+	if len(l) == 0 {
+		return nil
+	}
+
+	return &InvalidError{what, l}
+}
+
+// Validation error, values were reset to defaults and config is still usable
+type InvalidError struct {
+	What   string
+	Values []string
+}
+
+// Returns error message
+func (e *InvalidError) Error() string {
+	return "invalid " + e.What + ": " + strings.Join(e.Values, ", ")
+}
+
+// Checks preferences, invalid values are reset to defaults
+func (p *Preferences) Validate() error {
+	d := &Preferences{}
+	d.Default()
+
+	var invalid invalidList
+
+	if p.MaxFps <= 0 {
+		invalid.add("MaxFps %d must be positive", p.MaxFps)
+		p.MaxFps = d.MaxFps
+	}
+
+	switch p.Transition {
+	case TRANSITION_NONE, TRANSITION_FADE, TRANSITION_CROSSFADE, TRANSITION_SLIDE:
+	default:
+		invalid.add("unknown Transition %q", p.Transition)
+		p.Transition = d.Transition
+	}
+
+	if p.TransitionLength < 0 {
+		invalid.add("TransitionLength %d must not be negative", p.TransitionLength)
+		p.TransitionLength = d.TransitionLength
+	}
+
+	if p.GifSeconds < 0 || p.GifFps < 0 || p.GifWidth < 0 {
+		invalid.add("GifSeconds, GifFps and GifWidth must not be negative")
+		p.GifSeconds, p.GifFps, p.GifWidth = d.GifSeconds, d.GifFps, d.GifWidth
+	}
+
+	if p.StickDeadzone < 0 || p.StickDeadzone >= 1 {
+		invalid.add("StickDeadzone %g must be in range 0..1", p.StickDeadzone)
+		p.StickDeadzone = d.StickDeadzone
+	}
+
+	if p.AccelSmoothing < 0 || p.AccelSmoothing >= 1 {
+		invalid.add("AccelSmoothing %g must be in range 0..1", p.AccelSmoothing)
+		p.AccelSmoothing = d.AccelSmoothing
+	}
+
+	if p.AccelSensitivity <= 0 || p.DragRadius <= 0 {
+		invalid.add("AccelSensitivity %g and DragRadius %g must be positive", p.AccelSensitivity, p.DragRadius)
+		p.AccelSensitivity, p.DragRadius = d.AccelSensitivity, d.DragRadius
+	}
+
+	return invalid.err("config")
+}
+
+// Checks tuning, invalid values are reset to defaults
+func (t *Tuning) Validate() error {
+	d := &Tuning{}
+	d.Default()
+
+	var invalid invalidList
+
+	if t.MaxShipDots <= 0 || t.NShipDotsArray <= 0 || t.MaxShipDots%t.NShipDotsArray != 0 {
+		invalid.add("NShipDotsArray %d must divide MaxShipDots %d", t.NShipDotsArray, t.MaxShipDots)
+		t.MaxShipDots, t.NShipDotsArray = d.MaxShipDots, d.NShipDotsArray
+	}
+
+	if t.MaxBangDots <= 0 || t.NBangDotsArray <= 0 || t.MaxBangDots%t.NBangDotsArray != 0 {
+		invalid.add("NBangDotsArray %d must divide MaxBangDots %d", t.NBangDotsArray, t.MaxBangDots)
+		t.MaxBangDots, t.NBangDotsArray = d.MaxBangDots, d.NBangDotsArray
+	}
+
+	if t.NDustMotes <= 0 || t.NDustArray <= 0 || t.NDustMotes%t.NDustArray != 0 {
+		invalid.add("NDustArray %d must divide NDustMotes %d", t.NDustArray, t.NDustMotes)
+		t.NDustMotes, t.NDustArray = d.NDustMotes, d.NDustArray
+	}
+
+	if t.EngineDots < 0 || t.EngineDots > t.MaxShipDots {
+		invalid.add("EngineDots %d must be in range 0..MaxShipDots", t.EngineDots)
+		t.EngineDots = d.EngineDots
+	}
+
+	if t.NRocks <= 0 || t.MaxRocks <= 0 {
+		invalid.add("NRocks %d and MaxRocks %d must be positive", t.NRocks, t.MaxRocks)
+		t.NRocks, t.MaxRocks = d.NRocks, d.MaxRocks
+	}
+
+	if t.InitialRocks < 0 || t.FinalRocks < t.InitialRocks || t.FinalRocks > t.MaxRocks {
+		invalid.add("InitialRocks %d and FinalRocks %d must be in order up to MaxRocks", t.InitialRocks, t.FinalRocks)
+		t.InitialRocks, t.FinalRocks = d.InitialRocks, d.FinalRocks
+	}
+
+	if t.MaxPowups <= 0 {
+		invalid.add("MaxPowups %d must be positive", t.MaxPowups)
+		t.MaxPowups = d.MaxPowups
+	}
+
+	if t.NFrames == 0 {
+		invalid.add("NFrames must be positive")
+		t.NFrames = d.NFrames
+	}
+
+	if t.NScores <= 0 {
+		invalid.add("NScores %d must be positive", t.NScores)
+		t.NScores = d.NScores
+	}
+
+	if t.StepLength <= 0 || t.MaxSteps <= 0 {
+		invalid.add("StepLength %d and MaxSteps %d must be positive", t.StepLength, t.MaxSteps)
+		t.StepLength, t.MaxSteps = d.StepLength, d.MaxSteps
+	}
+
+	if t.GameSpeed <= 0 {
+		invalid.add("GameSpeed %g must be positive", t.GameSpeed)
+		t.GameSpeed = d.GameSpeed
+	}
+
+	return invalid.err("tuning")
+}
@@ -0,0 +1,92 @@
+// VoV engine
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	m := map[string]interface{}{
+		"MusicEnabled": false,
+		"WinWidth":     800.0,
+		"KeyBindings":  nil,
+	}
+
+	if _, err := Migrate(m); err != nil {
+		t.Fatal(err)
+	}
+
+	if m["Version"] != ConfigVersion {
+		t.Errorf("version %v, want %d", m["Version"], ConfigVersion)
+	}
+
+	for _, key := range []string{"WinWidth", "KeyBindings"} {
+		if _, ok := m[key]; ok {
+			t.Errorf("%s not removed", key)
+		}
+	}
+
+	if m["MusicEnabled"] != false {
+		t.Errorf("MusicEnabled %v, want false", m["MusicEnabled"])
+	}
+
+	// Tuning and runtime values are not preferences
+	m = map[string]interface{}{"Version": 2.0, "GameSpeed": 2.0, "Speed": 2.0}
+
+	if _, err := Migrate(m); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"GameSpeed", "Speed"} {
+		if _, ok := m[key]; ok {
+			t.Errorf("%s not removed", key)
+		}
+	}
+
+	if _, err := Migrate(map[string]interface{}{"Version": float64(ConfigVersion + 1)}); err == nil {
+		t.Error("newer version: no error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	d := &Config{}
+	d.Default()
+
+	if err := d.Preferences.Validate(); err != nil {
+		t.Errorf("default preferences: %s", err)
+	}
+
+	if err := d.Tuning.Validate(); err != nil {
+		t.Errorf("default tuning: %s", err)
+	}
+
+	p := d.Preferences
+	p.MaxFps = 0
+	p.Transition = "wipe"
+	p.StickDeadzone = 1
+
+	err := p.Validate()
+	if e, ok := err.(*InvalidError); !ok || len(e.Values) != 3 {
+		t.Errorf("error %v, want 3 invalid values", err)
+	}
+
+	if p.MaxFps != d.MaxFps || p.Transition != d.Transition || p.StickDeadzone != d.StickDeadzone {
+		t.Errorf("invalid values not reset: %d %q %g", p.MaxFps, p.Transition, p.StickDeadzone)
+	}
+
+	tu := d.Tuning
+	tu.NShipDotsArray = 7
+	tu.FinalRocks = tu.MaxRocks + 1
+
+	err = tu.Validate()
+	if e, ok := err.(*InvalidError); !ok || len(e.Values) != 2 {
+		t.Errorf("error %v, want 2 invalid values", err)
+	} else if want := "NShipDotsArray 7 must divide MaxShipDots"; !strings.HasPrefix(e.Values[0], want) {
+		t.Errorf("error %q, want %q", e.Values[0], want)
+	}
+
+	if tu.NShipDotsArray != d.NShipDotsArray || tu.FinalRocks != d.FinalRocks {
+		t.Errorf("invalid values not reset: %d %d", tu.NShipDotsArray, tu.FinalRocks)
+	}
+}
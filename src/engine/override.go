@@ -0,0 +1,133 @@
+// VoV engine
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Preference overridden from command line or environment
+type Override struct {
+	// Value before override
+	Original interface{}
+	// Value set by override
+	Value interface{}
+}
+
+// Sets config field by name from string, overridden preferences are not saved
+func (c *Config) Set(key, value string) error {
+	field := reflect.ValueOf(c).Elem().FieldByName(key)
+	if !field.IsValid() || !field.CanSet() || key == "Preferences" || key == "Tuning" || key == "Runtime" || key == "Session" {
+		return fmt.Errorf("unknown config field %s", key)
+	}
+
+	original := field.Interface()
+
+	switch field.Kind() {
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		field.SetBool(v)
+
+	case reflect.Int, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		field.SetInt(v)
+
+	case reflect.Uint32:
+		v, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		field.SetUint(v)
+
+	case reflect.Float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		field.SetFloat(v)
+
+	case reflect.String:
+		field.SetString(value)
+
+	default:
+		return fmt.Errorf("config field %s can't be set from string", key)
+	}
+
+	// Remember value from file
+	var overrides *map[string]Override
+	if _, ok := reflect.TypeOf(c.Preferences).FieldByName(key); ok {
+		overrides = &c.Overrides
+	} else if _, ok := reflect.TypeOf(c.Tuning).FieldByName(key); ok {
+		overrides = &c.TuningOverrides
+	}
+
+	if overrides != nil {
+		if *overrides == nil {
+			*overrides = make(map[string]Override)
+		}
+
+		if o, ok := (*overrides)[key]; ok {
+			original = o.Original
+		}
+
+		(*overrides)[key] = Override{original, field.Interface()}
+	}
+
+	return nil
+}
+
+// Sets config fields from list of Key=Value pairs, config is changed only if all pairs are valid
+func (c *Config) SetAll(pairs []string) error {
+	// Check pairs on copy first
+	t := *c
+	t.Overrides = nil
+	t.TuningOverrides = nil
+
+	var invalid []string
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			invalid = append(invalid, fmt.Sprintf("invalid setting %q, expected Key=Value", pair))
+			continue
+		}
+
+		err := t.Set(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+		if err != nil {
+			invalid = append(invalid, err.Error())
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("%s", strings.Join(invalid, "; "))
+	}
+
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		c.Set(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+
+	return nil
+}
+
+// Saves preferences, overridden values that were not changed since are saved with value from file
+func (c *Config) Save() {
+	p := c.Preferences
+
+	v := reflect.ValueOf(&p).Elem()
+	for key, o := range c.Overrides {
+		field := v.FieldByName(key)
+		if reflect.DeepEqual(field.Interface(), o.Value) {
+			field.Set(reflect.ValueOf(o.Original))
+		}
+	}
+
+	p.Save()
+}
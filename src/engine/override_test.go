@@ -0,0 +1,70 @@
+// VoV engine
+package engine
+
+import (
+	"testing"
+)
+
+func TestSetAll(t *testing.T) {
+	c := &Config{}
+	c.Default()
+
+	err := c.SetAll([]string{"MaxFps=30", "GameSpeed = 1.5", "Seed=42", "MusicEnabled=false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.MaxFps != 30 || c.GameSpeed != 1.5 || c.Seed != 42 || c.MusicEnabled {
+		t.Errorf("max fps %d game speed %g seed %d music %v", c.MaxFps, c.GameSpeed, c.Seed, c.MusicEnabled)
+	}
+
+	// Session values are not remembered as overrides
+	if len(c.Overrides) != 2 || c.Overrides["MaxFps"].Original != 60 {
+		t.Errorf("overrides %v, want MaxFps and MusicEnabled", c.Overrides)
+	}
+
+	if len(c.TuningOverrides) != 1 || c.TuningOverrides["GameSpeed"].Value != 1.5 {
+		t.Errorf("tuning overrides %v, want GameSpeed", c.TuningOverrides)
+	}
+
+	// Nothing is applied if any pair is invalid
+	d := &Config{}
+	d.Default()
+
+	for _, pairs := range [][]string{
+		{"MaxFps=20", "Nope=1"},
+		{"MaxFps=20", "MaxFps"},
+		{"MaxFps=20", "ShowFps=maybe"},
+		{"MaxFps=20", "Runtime=1"},
+		{"MaxFps=20", "Session=1"},
+	} {
+		if err := d.SetAll(pairs); err == nil {
+			t.Errorf("%v: no error", pairs)
+		}
+
+		if d.MaxFps != 60 || len(d.Overrides) != 0 {
+			t.Errorf("%v: max fps %d overrides %v, want unchanged", pairs, d.MaxFps, d.Overrides)
+		}
+	}
+}
+
+func TestSave(t *testing.T) {
+	tempConfigFiles(t)
+
+	c := NewConfig()
+	c.SetAll([]string{"MaxFps=30", "ShowFps=true"})
+
+	// Changed override is saved, unchanged keeps value from file
+	c.ShowFps = false
+	c.Save()
+
+	p := Preferences{}
+	p.Default()
+	if _, err := p.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.MaxFps != 60 || p.ShowFps {
+		t.Errorf("saved max fps %d show fps %v, want 60 false", p.MaxFps, p.ShowFps)
+	}
+}
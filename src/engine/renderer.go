@@ -0,0 +1,96 @@
+// VoV engine
+package engine
+
+import (
+	"image"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/gen2brain/vov/src/system/log"
+)
+
+// Renderer interface, game code draws only through it
+type Renderer interface {
+	Clear() error
+	Present()
+	Destroy() error
+
+	SetLogicalSize(w, h int) error
+	GetOutputSize() (int, int, error)
+
+	SetDrawColor(r, g, b, a uint8) error
+	SetDrawBlendMode(bm sdl.BlendMode) error
+
+	DrawPoints(points []sdl.Point) error
+	DrawRect(rect *sdl.Rect) error
+	FillRect(rect *sdl.Rect) error
+
+	Copy(texture *sdl.Texture, src, dst *sdl.Rect) error
+	CreateTexture(format uint32, access int, w, h int) (*sdl.Texture, error)
+	CreateTextureFromSurface(surface *sdl.Surface) (*sdl.Texture, error)
+	SetRenderTarget(texture *sdl.Texture) error
+
+	ReadPixels(rect *sdl.Rect, format uint32, pixels unsafe.Pointer, pitch int) error
+}
+
+// SDL window renderer
+type SDLRenderer struct {
+	*sdl.Renderer
+}
+
+// Returns new SDL renderer for window
+func NewSDLRenderer(window *sdl.Window) (r *SDLRenderer, err error) {
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return
+	}
+
+	r = &SDLRenderer{renderer}
+	return
+}
+
+// In-memory software renderer, frames can be inspected with Image
+type ImageRenderer struct {
+	*sdl.Renderer
+
+	// Target surface
+	Surface *sdl.Surface
+}
+
+// Returns new image renderer of given size
+func NewImageRenderer(width, height int) (r *ImageRenderer, err error) {
+	surface, err := sdl.CreateRGBSurface(0, int32(width), int32(height), 32, 0x000000ff, 0x0000ff00, 0x00ff0000, 0xff000000)
+	if err != nil {
+		return
+	}
+
+	renderer, err := sdl.CreateSoftwareRenderer(surface)
+	if err != nil {
+		surface.Free()
+		return
+	}
+
+	r = &ImageRenderer{renderer, surface}
+	return
+}
+
+// Returns copy of rendered frame
+func (r *ImageRenderer) Image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(r.Surface.W), int(r.Surface.H)))
+
+	// ABGR8888 is RGBA byte order on little endian
+	err := r.ReadPixels(nil, sdl.PIXELFORMAT_ABGR8888, unsafe.Pointer(&img.Pix[0]), img.Stride)
+	if err != nil {
+		log.Error("ReadPixels: %s\n", err)
+	}
+
+	return img
+}
+
+// Destroys renderer and frees surface
+func (r *ImageRenderer) Destroy() error {
+	err := r.Renderer.Destroy()
+	r.Surface.Free()
+	return err
+}
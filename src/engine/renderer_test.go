@@ -0,0 +1,75 @@
+// VoV engine
+package engine
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestImageRenderer(t *testing.T) {
+	c := &Config{}
+	c.Default()
+
+	e := NewEngine(c)
+	if err := e.InitOffscreen(64, 48); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Destroy()
+
+	// Draw frame through renderer interface
+	e.Clear()
+
+	e.Renderer.SetDrawColor(255, 0, 0, 255)
+	e.Renderer.FillRect(&sdl.Rect{10, 10, 4, 4})
+
+	e.Renderer.SetDrawColor(0, 255, 0, 255)
+	e.Renderer.DrawPoints([]sdl.Point{{30, 20}, {31, 20}})
+
+	e.Renderer.SetDrawColor(0, 0, 255, 255)
+	e.Renderer.DrawRect(&sdl.Rect{40, 30, 10, 10})
+
+	img := e.Renderer.(*ImageRenderer).Image()
+
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 48 {
+		t.Fatalf("image size %dx%d, want 64x48", b.Dx(), b.Dy())
+	}
+
+	black := color.RGBA{0, 0, 0, 255}
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{0, 0, black},
+		{10, 10, red},
+		{13, 13, red},
+		{14, 14, black},
+		{30, 20, green},
+		{31, 20, green},
+		{32, 20, black},
+		{40, 30, blue},
+		{49, 39, blue},
+		{45, 35, black},
+	}
+
+	for _, tt := range tests {
+		if got := img.RGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel %d,%d = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	// Capture reads the same frame at logical size
+	capture, err := e.Capture()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := capture.RGBAAt(10, 10); got != red {
+		t.Errorf("captured pixel 10,10 = %v, want %v", got, red)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"math/rand"
 	"path/filepath"
+	"strings"
+	"time"
 	"unsafe"
 
 	"github.com/veandco/go-sdl2/sdl"
@@ -81,6 +83,11 @@ type Resource struct {
 
 	DataDir string
 
+	// Seed of random number generator used for rocks
+	Seed int64
+	// Random number generator
+	Rand *rand.Rand
+
 	Mappings []string
 
 	FontMain   *ttf.Font
@@ -105,6 +112,13 @@ type Resource struct {
 	MusicMenu *mix.Music
 	MusicGame *mix.Music
 
+	// Current music, nil if none or fading out
+	Music *mix.Music
+
+	// Music waiting for fade out of current music
+	NextMusic *mix.Music
+	NextLoops int
+
 	Ship     *sdl.Texture
 	ShipSurf *sdl.Surface
 	ShipGlow *sdl.Texture
@@ -127,6 +141,8 @@ type Resource struct {
 
 	StartText     *sdl.Texture
 	StartTextHi   *sdl.Texture
+	DailyText     *sdl.Texture
+	DailyTextHi   *sdl.Texture
 	ScoresText    *sdl.Texture
 	ScoresTextHi  *sdl.Texture
 	OptionsText   *sdl.Texture
@@ -161,6 +177,12 @@ type Resource struct {
 	HapticTextHi        *sdl.Texture
 	ShowFpsText         *sdl.Texture
 	ShowFpsTextHi       *sdl.Texture
+	GhostText           *sdl.Texture
+	GhostTextHi         *sdl.Texture
+	StickText           *sdl.Texture
+	StickTextHi         *sdl.Texture
+	ControlsText        *sdl.Texture
+	ControlsTextHi      *sdl.Texture
 
 	YesText *sdl.Texture
 	NoText  *sdl.Texture
@@ -217,6 +239,12 @@ func NewResource(e *Engine, d string) (r *Resource) {
 	r.Engine = e
 	r.DataDir = d
 
+	if e.Cfg.Seed != 0 {
+		r.SetSeed(e.Cfg.Seed)
+	} else {
+		r.SetSeed(time.Now().UTC().UnixNano())
+	}
+
 	r.Rocks = make([]*sdl.Texture, e.Cfg.NRocks)
 	r.RocksSurf = make([]*sdl.Surface, e.Cfg.NRocks)
 
@@ -232,6 +260,10 @@ func NewResource(e *Engine, d string) (r *Resource) {
 		"`", "~", "!", "@", "#", "$", "%", "&", "*", "(", ")", "-", "_", "=", "+", "[", "]", "{", "}", ":", ";", "'", "\"", ".", ",", "<", ">", "/", "?", " ",
 	}
 
+	if e.Headless {
+		return
+	}
+
 	r.LoadMappings()
 
 	r.FontMain = r.LoadFont(fontMain, fontMainSize)
@@ -242,26 +274,16 @@ func NewResource(e *Engine, d string) (r *Resource) {
 
 // Loads resources
 func (r *Resource) Load() {
+	if r.Engine.Headless {
+		r.LoadSurfaces()
+		return
+	}
+
 	r.FontSmall = r.LoadFont(fontSmall, fontSmallSize)
 	r.FontTitle = r.LoadFont(fontTitle, fontTitleSize)
 	r.FontMedium = r.LoadFont(fontMain, fontMediumSize)
 
-	r.SoundClick = r.LoadSound(soundClick)
-	r.SoundBounce = r.LoadSound(soundBounce)
-	r.SoundEngine1 = r.LoadSound(soundEngine1)
-	r.SoundEngine2 = r.LoadSound(soundEngine2)
-	r.SoundEngine3 = r.LoadSound(soundEngine3)
-	r.SoundPowup0 = r.LoadSound(soundPowup0)
-	r.SoundPowup1 = r.LoadSound(soundPowup1)
-	r.SoundPowup2 = r.LoadSound(soundPowup2)
-	r.SoundPowup3 = r.LoadSound(soundPowup3)
-	r.SoundPowup4 = r.LoadSound(soundPowup4)
-	r.SoundPowup5 = r.LoadSound(soundPowup5)
-	r.SoundExplosion1 = r.LoadSound(soundExplosion1)
-	r.SoundExplosion2 = r.LoadSound(soundExplosion2)
-
-	r.MusicMenu = r.LoadMusic(musicMenu)
-	r.MusicGame = r.LoadMusic(musicGame)
+	r.LoadAudio()
 
 	r.Background1 = r.LoadTexture(imageBackground1)
 	r.Background2 = r.LoadTexture(imageBackground2)
@@ -273,6 +295,8 @@ func (r *Resource) Load() {
 
 	r.StartText = r.RenderText(r.FontMain, "S T A R T", brown, true, 0)
 	r.StartTextHi = r.RenderText(r.FontMain, "S T A R T", white, true, 0)
+	r.DailyText = r.RenderText(r.FontMain, "D A I L Y", brown, true, 0)
+	r.DailyTextHi = r.RenderText(r.FontMain, "D A I L Y", white, true, 0)
 	r.ScoresText = r.RenderText(r.FontMain, "H A L L  O F  F A M E", brown, true, 0)
 	r.ScoresTextHi = r.RenderText(r.FontMain, "H A L L  O F  F A M E", white, true, 0)
 	r.OptionsText = r.RenderText(r.FontMain, "O P T I O N S", brown, true, 0)
@@ -290,6 +314,12 @@ func (r *Resource) Load() {
 	r.HapticTextHi = r.RenderText(r.FontMain, "R U M B L E :", white, true, 0)
 	r.ShowFpsText = r.RenderText(r.FontMain, "S H O W  F P S :", brown, true, 0)
 	r.ShowFpsTextHi = r.RenderText(r.FontMain, "S H O W  F P S :", white, true, 0)
+	r.GhostText = r.RenderText(r.FontMain, "G H O S T :", brown, true, 0)
+	r.GhostTextHi = r.RenderText(r.FontMain, "G H O S T :", white, true, 0)
+	r.StickText = r.RenderText(r.FontMain, "S T I C K :", brown, true, 0)
+	r.StickTextHi = r.RenderText(r.FontMain, "S T I C K :", white, true, 0)
+	r.ControlsText = r.RenderText(r.FontMain, "C O N T R O L S", brown, true, 0)
+	r.ControlsTextHi = r.RenderText(r.FontMain, "C O N T R O L S", white, true, 0)
 
 	r.ProgrammingText = r.RenderText(r.FontSmall, "Programming", red, true, 0)
 	r.ProgrammingCreditText = r.RenderText(r.FontMedium, "M i l a n  N i k o l i c  (github.com/gen2brain)", green, true, 0)
@@ -351,12 +381,35 @@ func (r *Resource) Load() {
 	r.LoadGlyphs()
 }
 
-// Frees resources
-func (r *Resource) Free() {
-	r.FontMain.Close()
-	r.FontSmall.Close()
-	r.FontTitle.Close()
-	r.FontMedium.Close()
+// Loads sounds and music
+func (r *Resource) LoadAudio() {
+	if !r.Engine.Audio() {
+		return
+	}
+
+	r.SoundClick = r.LoadSound(soundClick)
+	r.SoundBounce = r.LoadSound(soundBounce)
+	r.SoundEngine1 = r.LoadSound(soundEngine1)
+	r.SoundEngine2 = r.LoadSound(soundEngine2)
+	r.SoundEngine3 = r.LoadSound(soundEngine3)
+	r.SoundPowup0 = r.LoadSound(soundPowup0)
+	r.SoundPowup1 = r.LoadSound(soundPowup1)
+	r.SoundPowup2 = r.LoadSound(soundPowup2)
+	r.SoundPowup3 = r.LoadSound(soundPowup3)
+	r.SoundPowup4 = r.LoadSound(soundPowup4)
+	r.SoundPowup5 = r.LoadSound(soundPowup5)
+	r.SoundExplosion1 = r.LoadSound(soundExplosion1)
+	r.SoundExplosion2 = r.LoadSound(soundExplosion2)
+
+	r.MusicMenu = r.LoadMusic(musicMenu)
+	r.MusicGame = r.LoadMusic(musicGame)
+}
+
+// Frees sounds and music
+func (r *Resource) FreeAudio() {
+	if !r.Engine.Audio() {
+		return
+	}
 
 	r.SoundClick.Free()
 	r.SoundBounce.Free()
@@ -374,6 +427,37 @@ func (r *Resource) Free() {
 
 	r.MusicMenu.Free()
 	r.MusicGame.Free()
+}
+
+// Loads only surfaces needed for collisions
+func (r *Resource) LoadSurfaces() {
+	r.ShipSurf = r.LoadSurface(imageShip)
+	r.PowupSurf = r.LoadSurface(imagePowup)
+
+	r.LoadRocks()
+}
+
+// Frees surfaces needed for collisions
+func (r *Resource) FreeSurfaces() {
+	r.ShipSurf.Free()
+	r.PowupSurf.Free()
+
+	r.FreeRocks()
+}
+
+// Frees resources
+func (r *Resource) Free() {
+	if r.Engine.Headless {
+		r.FreeSurfaces()
+		return
+	}
+
+	r.FontMain.Close()
+	r.FontSmall.Close()
+	r.FontTitle.Close()
+	r.FontMedium.Close()
+
+	r.FreeAudio()
 
 	r.Background1.Destroy()
 	r.Background2.Destroy()
@@ -386,6 +470,8 @@ func (r *Resource) Free() {
 
 	r.StartText.Destroy()
 	r.StartTextHi.Destroy()
+	r.DailyText.Destroy()
+	r.DailyTextHi.Destroy()
 	r.ScoresText.Destroy()
 	r.ScoresTextHi.Destroy()
 	r.OptionsText.Destroy()
@@ -449,10 +535,23 @@ func (r *Resource) Free() {
 	r.FreeGlyphs()
 }
 
+// Sets seed of random number generator
+func (r *Resource) SetSeed(seed int64) {
+	r.Seed = seed
+	r.Rand = rand.New(rand.NewSource(seed))
+}
+
+// Reloads rocks with given seed
+func (r *Resource) ReloadRocks(seed int64) {
+	r.FreeRocks()
+	r.SetSeed(seed)
+	r.LoadRocks()
+}
+
 // Loads rocks
 func (r *Resource) LoadRocks() {
 	rnd := func(min, max int) int {
-		return rand.Intn(max-min) + min
+		return r.Rand.Intn(max-min) + min
 	}
 
 	for i := 0; i < r.Engine.Cfg.NRocks; i++ {
@@ -478,23 +577,32 @@ func (r *Resource) LoadRocks() {
 
 		s.Free()
 
+		r.RocksSurf[i] = d
+
+		if r.Engine.Renderer == nil {
+			continue
+		}
+
 		texture, err := r.Engine.Renderer.CreateTextureFromSurface(d)
 		if err != nil {
 			log.Error("CreateTextureFromSurface: %s\n", err)
 		}
 
 		r.Rocks[i] = texture
-		r.RocksSurf[i] = d
 	}
 }
 
 // Frees rocks
 func (r *Resource) FreeRocks() {
 	for _, t := range r.Rocks {
-		t.Destroy()
+		if t != nil {
+			t.Destroy()
+		}
 	}
 	for _, s := range r.RocksSurf {
-		s.Free()
+		if s != nil {
+			s.Free()
+		}
 	}
 }
 
@@ -552,7 +660,14 @@ func (r *Resource) LoadTexture(filename string) (image *sdl.Texture) {
 
 	file := filepath.Join(r.DataDir, "images", filename)
 
-	image, err = img.LoadTexture(r.Engine.Renderer, file)
+	surface, err := img.Load(file)
+	if err != nil {
+		log.Error("LoadTexture: %s\n", err)
+		return
+	}
+	defer surface.Free()
+
+	image, err = r.Engine.Renderer.CreateTextureFromSurface(surface)
 	if err != nil {
 		log.Error("LoadTexture: %s\n", err)
 	}
@@ -699,9 +814,53 @@ func (r *Resource) DrawText(text string, x, y int32, font int) {
 	}
 }
 
+// Draws engine message at bottom of screen, wrapped to window width
+func (r *Resource) DrawMessage() {
+	e := r.Engine
+	if e.Message == "" {
+		return
+	}
+
+	if e.UIClock.Ticks()-e.MessageTicks > MESSAGE_LENGTH {
+		e.Message = ""
+		return
+	}
+
+	font := FONT_SMALL
+	if e.MessageError {
+		font = FONT_SMALL_RED
+	}
+
+	maxWidth := int(e.Cfg.WinWidth) - 20
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(e.Message) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+
+		if w, _, _ := r.FontSmall.SizeUTF8(next); w > maxWidth && line != "" {
+			lines = append(lines, line)
+			next = word
+		}
+
+		line = next
+	}
+	lines = append(lines, line)
+
+	_, h, _ := r.FontSmall.SizeUTF8(e.Message)
+	y := int32(e.Cfg.WinHeight) - int32(len(lines)*h) - 10
+
+	for i, l := range lines {
+		r.DrawText(l, 10, y+int32(i*h), font)
+	}
+}
+
 // Plays sound
 func (r *Resource) PlaySound(sound *mix.Chunk, channel int, loops int) {
-	if r.Engine.Cfg.SoundsEnabled {
+	if r.Engine.Cfg.SoundsEnabled && r.Engine.Audio() {
 		_, err := sound.Play(channel, loops)
 		if err != nil {
 			log.Error("Play: %s\n", err)
@@ -711,7 +870,7 @@ func (r *Resource) PlaySound(sound *mix.Chunk, channel int, loops int) {
 
 // Plays sound timed
 func (r *Resource) PlaySoundTimed(sound *mix.Chunk, channel int, loops int, ticks int) {
-	if r.Engine.Cfg.SoundsEnabled {
+	if r.Engine.Cfg.SoundsEnabled && r.Engine.Audio() {
 		_, err := sound.PlayTimed(channel, loops, ticks)
 		if err != nil {
 			log.Error("PlayTimed: %s\n", err)
@@ -719,12 +878,102 @@ func (r *Resource) PlaySoundTimed(sound *mix.Chunk, channel int, loops int, tick
 	}
 }
 
-// Plays music
+// Checks if sound is playing on channel
+func (r *Resource) Playing(channel int) bool {
+	if !r.Engine.Audio() {
+		return false
+	}
+
+	return mix.Playing(channel) != 0
+}
+
+// Fades out sound on channel
+func (r *Resource) FadeOutChannel(channel int, ms int) {
+	if !r.Engine.Audio() {
+		return
+	}
+
+	mix.FadeOutChannel(channel, ms)
+}
+
+// Plays music, waits for music that is fading out
 func (r *Resource) PlayMusic(music *mix.Music, loops int) {
-	if r.Engine.Cfg.MusicEnabled {
-		err := music.FadeIn(loops, 200)
+	if r.Engine.Cfg.MusicEnabled && r.Engine.Audio() {
+		// Starting music would block until fade out is done
+		if mix.FadingMusic() == mix.FADING_OUT {
+			r.NextMusic = music
+			r.NextLoops = loops
+			return
+		}
+
+		err := music.FadeIn(loops, r.MusicFadeLength())
 		if err != nil {
 			log.Error("Play: %s\n", err)
+			return
 		}
+
+		r.Music = music
 	}
 }
+
+// Plays waiting music when previous music faded out
+func (r *Resource) UpdateMusic() {
+	if r.NextMusic == nil || !r.Engine.Audio() || mix.FadingMusic() == mix.FADING_OUT {
+		return
+	}
+
+	music := r.NextMusic
+	r.NextMusic = nil
+
+	r.PlayMusic(music, r.NextLoops)
+}
+
+// Checks if music is playing or waiting to be played, other music doesn't count
+func (r *Resource) PlayingMusic(music *mix.Music) bool {
+	if !r.Engine.Audio() {
+		return false
+	}
+
+	if r.NextMusic != nil {
+		return r.NextMusic == music
+	}
+
+	return r.Music == music && mix.PlayingMusic() && mix.FadingMusic() != mix.FADING_OUT
+}
+
+// Fades out music during state transition
+func (r *Resource) FadeOutMusic() {
+	if !r.Engine.Audio() {
+		return
+	}
+
+	r.Music = nil
+	r.NextMusic = nil
+
+	if !r.Engine.State.Animated() {
+		mix.HaltMusic()
+		return
+	}
+
+	mix.FadeOutMusic(r.Engine.Cfg.TransitionLength / 2)
+}
+
+// Returns music fade in length in milliseconds
+func (r *Resource) MusicFadeLength() int {
+	if r.Engine.State.Animated() && r.Engine.Cfg.TransitionLength/2 > 200 {
+		return r.Engine.Cfg.TransitionLength / 2
+	}
+
+	return 200
+}
+
+// Halts music
+func (r *Resource) HaltMusic() {
+	if !r.Engine.Audio() {
+		return
+	}
+
+	r.Music = nil
+	r.NextMusic = nil
+	mix.HaltMusic()
+}
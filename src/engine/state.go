@@ -13,44 +13,72 @@ type State interface {
 	Draw()
 }
 
+// Overlay state interface, states below overlay are drawn beneath it
+type Overlay interface {
+	// Returns true if states below are not updated
+	Frozen() bool
+}
+
 // State machine
 type StateMachine struct {
+	Engine *Engine
+
 	states []State
+
+	// State to change to after next draw
+	next State
+	// Active transition
+	transition *Transition
 }
 
-// Push state
+// Push state on top of current state
 func (g *StateMachine) Push(state State) {
 	g.states = append(g.states, state)
 
 	g.states[g.State()].OnInit()
 }
 
-// Pop state
+// Pop state, state below becomes current
 func (g *StateMachine) Pop() {
 	if g.Size() != 0 {
-		if g.states[g.State()].OnQuit() {
-			tmp, states := g.states[g.State()], g.states[:g.State()]
-			g.states = append(states, tmp)
-		}
+		g.states[g.State()].OnQuit()
+
+		g.states[g.State()] = nil
+		g.states = g.states[:g.State()]
 	}
 }
 
-// Change state
+// Change state, all states are removed
 func (g *StateMachine) Change(state State) {
 	if g.Size() != 0 {
 		if g.states[g.State()].String() == state.String() {
 			return
 		}
 
-		if g.states[g.State()].OnQuit() {
-			tmp, states := g.states[g.State()], g.states[:g.State()]
-			g.states = append(states, tmp)
+		// Outgoing frame is captured after it is drawn
+		if g.Animated() {
+			g.next = state
+			return
+		}
+
+		for g.Size() != 0 {
+			g.Pop()
 		}
 	}
 
 	g.Push(state)
 }
 
+// Checks if state changes are animated
+func (g *StateMachine) Animated() bool {
+	if g.Engine == nil || g.Engine.Renderer == nil {
+		return false
+	}
+
+	t := g.Engine.Cfg.Transition
+	return t != "" && t != TRANSITION_NONE && g.Engine.Cfg.TransitionLength > 0
+}
+
 // Returns size
 func (g *StateMachine) Size() int {
 	return len(g.states)
@@ -61,23 +89,97 @@ func (g *StateMachine) State() int {
 	return len(g.states) - 1
 }
 
-// Handles state events
+// Returns current state
+func (g *StateMachine) Top() State {
+	if g.Size() != 0 {
+		return g.states[g.State()]
+	}
+
+	return nil
+}
+
+// Returns index of lowest visible state
+func (g *StateMachine) bottom() int {
+	i := g.State()
+	for i > 0 {
+		if _, ok := g.states[i].(Overlay); !ok {
+			break
+		}
+		i--
+	}
+
+	return i
+}
+
+// Handles state events, only current state receives events
 func (g *StateMachine) HandleEvents() {
 	if g.Size() != 0 {
 		g.states[g.State()].HandleEvents()
 	}
 }
 
-// Updates state
+// Updates state and states below overlays that are not frozen
 func (g *StateMachine) Update() {
-	if g.Size() != 0 {
-		g.states[g.State()].Update()
+	if g.Size() == 0 {
+		return
+	}
+
+	i := g.State()
+	for i > 0 {
+		o, ok := g.states[i].(Overlay)
+		if !ok || o.Frozen() {
+			break
+		}
+		i--
+	}
+
+	// States can change while updating
+	for ; i < g.Size(); i++ {
+		g.states[i].Update()
 	}
 }
 
-// Draws state
+// Draws visible states from bottom to top
 func (g *StateMachine) Draw() {
-	if g.Size() != 0 {
-		g.states[g.State()].Draw()
+	if g.Size() == 0 {
+		return
+	}
+
+	// Incoming states are drawn off screen during transition
+	if g.transition != nil {
+		g.transition.Begin()
+	}
+
+	// States can change while drawing
+	for i := g.bottom(); i < g.Size(); i++ {
+		g.states[i].Draw()
+	}
+
+	if g.transition != nil {
+		g.transition.End()
+		g.transition.Draw()
+
+		if g.transition.Done() {
+			g.transition.Free()
+			g.transition = nil
+		}
+	}
+
+	if g.next != nil {
+		if g.transition != nil {
+			g.transition.Free()
+		}
+
+		// Outgoing frame stays on screen until next draw, interrupted transition is captured as drawn
+		g.transition = NewTransition(g.Engine)
+
+		state := g.next
+		g.next = nil
+
+		for g.Size() != 0 {
+			g.Pop()
+		}
+
+		g.Push(state)
 	}
 }
@@ -0,0 +1,150 @@
+// VoV engine
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+// State that logs calls
+type testState struct {
+	name string
+	log  *[]string
+}
+
+func (s *testState) OnInit() bool    { *s.log = append(*s.log, s.name+" init"); return true }
+func (s *testState) OnQuit() bool    { *s.log = append(*s.log, s.name+" quit"); return true }
+func (s *testState) String() string  { return s.name }
+func (s *testState) HandleEvents()   { *s.log = append(*s.log, s.name+" events") }
+func (s *testState) Update()         { *s.log = append(*s.log, s.name+" update") }
+func (s *testState) Draw()           { *s.log = append(*s.log, s.name+" draw") }
+func (s *testState) reset() []string { l := *s.log; *s.log = nil; return l }
+
+// Overlay state that logs calls
+type testOverlay struct {
+	testState
+	frozen bool
+}
+
+func (s *testOverlay) Frozen() bool {
+	return s.frozen
+}
+
+func TestStateStack(t *testing.T) {
+	c := &Config{}
+	c.Default()
+	c.Transition = TRANSITION_NONE
+
+	e := NewEngine(c)
+
+	var log []string
+	game := &testState{"game", &log}
+	hud := &testOverlay{testState{"hud", &log}, false}
+	pause := &testOverlay{testState{"pause", &log}, true}
+
+	check := func(what string, want ...string) {
+		t.Helper()
+		if got := game.reset(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: %v, want %v", what, got, want)
+		}
+	}
+
+	e.State.Push(game)
+	e.State.Push(hud)
+	check("push", "game init", "hud init")
+
+	// Overlay that is not frozen updates states below
+	e.State.Update()
+	check("update", "game update", "hud update")
+
+	e.State.Push(pause)
+	check("push frozen", "pause init")
+
+	// Frozen overlay stops updates below, all are drawn
+	e.State.Update()
+	check("update frozen", "pause update")
+
+	e.State.Draw()
+	check("draw", "game draw", "hud draw", "pause draw")
+
+	// Only top state gets events
+	e.State.HandleEvents()
+	check("events", "pause events")
+
+	e.State.Pop()
+	check("pop", "pause quit")
+
+	if e.State.Top() != hud {
+		t.Errorf("top %v, want hud", e.State.Top())
+	}
+
+	// Change removes states from top
+	menu := &testState{"menu", &log}
+	e.State.Change(menu)
+	check("change", "hud quit", "game quit", "menu init")
+
+	// Change to same state is ignored
+	e.State.Change(&testState{"menu", &log})
+	check("same change")
+
+	// States below non-overlay are not drawn
+	e.State.Push(game)
+	game.reset()
+	e.State.Draw()
+	check("draw covered", "game draw")
+}
+
+func TestStateTransition(t *testing.T) {
+	c := &Config{}
+	c.Default()
+	c.Transition = TRANSITION_FADE
+	c.TransitionLength = 100
+
+	e := NewEngine(c)
+	if err := e.InitOffscreen(64, 48); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Destroy()
+
+	source := &ManualTimeSource{}
+	e.UIClock = NewClock(source)
+	e.UIClock.Start()
+
+	var log []string
+	menu := &testState{"menu", &log}
+	game := &testState{"game", &log}
+
+	e.State.Change(menu)
+	menu.reset()
+
+	// Outgoing state is drawn once more before change
+	e.State.Change(game)
+
+	if e.State.Top() != menu {
+		t.Fatalf("top %v before draw, want menu", e.State.Top())
+	}
+
+	e.State.Draw()
+
+	if got, want := menu.reset(), []string{"menu draw", "menu quit", "game init"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("change: %v, want %v", got, want)
+	}
+
+	// Incoming state is drawn during transition
+	e.State.Draw()
+
+	if e.State.transition == nil {
+		t.Error("no transition after change")
+	}
+
+	source.Advance(100)
+	e.State.Draw()
+
+	if got, want := menu.reset(), []string{"game draw", "game draw"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("transition: %v, want %v", got, want)
+	}
+
+	if e.State.transition != nil {
+		t.Error("transition not freed when done")
+	}
+}
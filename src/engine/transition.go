@@ -0,0 +1,184 @@
+// VoV engine
+package engine
+
+import (
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/gen2brain/vov/src/system/log"
+)
+
+// Transition types
+const (
+	TRANSITION_NONE      = "none"
+	TRANSITION_FADE      = "fade"
+	TRANSITION_CROSSFADE = "crossfade"
+	TRANSITION_SLIDE     = "slide"
+)
+
+// Transition between states
+type Transition struct {
+	Engine *Engine
+
+	// Transition type
+	Type string
+
+	// Length in milliseconds
+	Length uint32
+	// UI clock ticks when transition started
+	Start uint32
+
+	// Last frame of outgoing state
+	Texture *sdl.Texture
+
+	// Incoming states are drawn to target while transition runs
+	Target *sdl.Texture
+}
+
+// Returns new transition from last drawn frame, nil if frame can't be captured
+func NewTransition(e *Engine) (t *Transition) {
+	img, err := e.Capture()
+	if err != nil {
+		log.Error("Capture: %s\n", err)
+		return
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	// ABGR8888 is RGBA byte order on little endian
+	texture, err := e.Renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STATIC, w, h)
+	if err != nil {
+		log.Error("CreateTexture: %s\n", err)
+		return
+	}
+
+	err = texture.Update(nil, unsafe.Pointer(&img.Pix[0]), img.Stride)
+	if err != nil {
+		log.Error("Update: %s\n", err)
+		texture.Destroy()
+		return
+	}
+
+	texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+
+	target, err := e.Renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_TARGET, w, h)
+	if err != nil {
+		log.Error("CreateTexture: %s\n", err)
+		texture.Destroy()
+		return
+	}
+
+	target.SetBlendMode(sdl.BLENDMODE_BLEND)
+
+	t = &Transition{}
+	t.Engine = e
+	t.Type = e.Cfg.Transition
+	t.Length = uint32(e.Cfg.TransitionLength)
+	t.Start = e.UIClock.Ticks()
+	t.Texture = texture
+	t.Target = target
+
+	return
+}
+
+// Returns transition progress from 0 to 1
+func (t *Transition) Progress() float64 {
+	p := float64(t.Engine.UIClock.Ticks()-t.Start) / float64(t.Length)
+	if p > 1 {
+		p = 1
+	}
+
+	return p
+}
+
+// Checks if transition is finished
+func (t *Transition) Done() bool {
+	return t.Progress() >= 1
+}
+
+// Redirects drawing of incoming states to target
+func (t *Transition) Begin() {
+	r := t.Engine.Renderer
+
+	err := r.SetRenderTarget(t.Target)
+	if err != nil {
+		log.Error("SetRenderTarget: %s\n", err)
+	}
+
+	r.SetDrawColor(0, 0, 0, 255)
+	r.Clear()
+}
+
+// Restores drawing to screen
+func (t *Transition) End() {
+	err := t.Engine.Renderer.SetRenderTarget(nil)
+	if err != nil {
+		log.Error("SetRenderTarget: %s\n", err)
+	}
+}
+
+// Draws outgoing frame and incoming states
+func (t *Transition) Draw() {
+	p := t.Progress()
+	r := t.Engine.Renderer
+
+	w, h := int32(t.Engine.Cfg.WinWidth), int32(t.Engine.Cfg.WinHeight)
+
+	r.SetDrawColor(0, 0, 0, 255)
+	r.Clear()
+
+	switch t.Type {
+	case TRANSITION_FADE:
+		// Fade out to black, then fade in
+		var alpha float64
+		if p < 0.5 {
+			t.Texture.SetAlphaMod(255)
+			r.Copy(t.Texture, nil, nil)
+			alpha = p * 2
+		} else {
+			t.Target.SetAlphaMod(255)
+			r.Copy(t.Target, nil, nil)
+			alpha = (1 - p) * 2
+		}
+
+		r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+		r.SetDrawColor(0, 0, 0, uint8(alpha*255))
+		r.FillRect(nil)
+
+	case TRANSITION_CROSSFADE:
+		// Incoming states fade in over outgoing frame
+		t.Texture.SetAlphaMod(255)
+		r.Copy(t.Texture, nil, nil)
+
+		t.Target.SetAlphaMod(uint8(p * 255))
+		r.Copy(t.Target, nil, nil)
+
+	case TRANSITION_SLIDE:
+		// Incoming states push outgoing frame to the left
+		x := int32(p * float64(w))
+
+		t.Texture.SetAlphaMod(255)
+		r.Copy(t.Texture, nil, &sdl.Rect{-x, 0, w, h})
+
+		t.Target.SetAlphaMod(255)
+		r.Copy(t.Target, nil, &sdl.Rect{w - x, 0, w, h})
+
+	default:
+		t.Target.SetAlphaMod(255)
+		r.Copy(t.Target, nil, nil)
+	}
+}
+
+// Frees transition
+func (t *Transition) Free() {
+	if t.Texture != nil {
+		t.Texture.Destroy()
+		t.Texture = nil
+	}
+
+	if t.Target != nil {
+		t.Target.Destroy()
+		t.Target = nil
+	}
+}
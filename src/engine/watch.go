@@ -0,0 +1,231 @@
+// VoV engine
+package engine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// How often to check watched files (milliseconds)
+	WATCH_INTERVAL = 500
+
+	// How long message is shown (milliseconds)
+	MESSAGE_LENGTH = 5000
+)
+
+// Tuning values that are applied on reload, others need restart
+var LiveTuning = []string{
+	"BarrierSpeed", "Bounciness", "ThrusterStrength", "DotMassUnit", "GameSpeed",
+	"KH", "KV", "RDX", "RDY", "InitialRocks", "FinalRocks",
+	"PowupsTimeout", "PowupStateTimeout", "PowupTextScale", "PowupTextTimeout",
+	"DeadPauseLength", "InvinciblePauseLength", "GameOverLength", "ScoresLength", "ScrollSpeed",
+}
+
+// Watcher structure, reloads config and tuning files when they change
+type Watcher struct {
+	Engine *Engine
+
+	// Modification times of files
+	ConfigTime time.Time
+	TuningTime time.Time
+
+	// Last check (UI clock milliseconds)
+	Ticks uint32
+}
+
+// Returns new watcher
+func NewWatcher(e *Engine) (w *Watcher) {
+	w = &Watcher{}
+	w.Engine = e
+	w.ConfigTime = modTime(e.Cfg.Preferences.File())
+	w.TuningTime = modTime(e.Cfg.Tuning.File())
+	w.Ticks = e.UIClock.Ticks()
+	return
+}
+
+// Starts watching config and tuning files
+func (e *Engine) Watch() {
+	e.Watcher = NewWatcher(e)
+}
+
+// Checks files and reloads those that changed
+func (w *Watcher) Check() {
+	ticks := w.Engine.UIClock.Ticks()
+	if ticks-w.Ticks < WATCH_INTERVAL {
+		return
+	}
+	w.Ticks = ticks
+
+	if t := modTime(w.Engine.Cfg.Preferences.File()); !t.Equal(w.ConfigTime) {
+		w.ConfigTime = t
+		if !t.IsZero() {
+			w.ReloadConfig()
+		}
+	}
+
+	if t := modTime(w.Engine.Cfg.Tuning.File()); !t.Equal(w.TuningTime) {
+		w.TuningTime = t
+		if !t.IsZero() {
+			w.ReloadTuning()
+		}
+	}
+}
+
+// Reloads preferences, overrides from command line are kept
+func (w *Watcher) ReloadConfig() {
+	e := w.Engine
+	c := e.Cfg
+
+	js, err := ioutil.ReadFile(c.Preferences.File())
+	if err != nil {
+		e.ShowError(err)
+		return
+	}
+
+	p := Preferences{}
+	p.Default()
+
+	_, err = p.Decode(js)
+	if _, ok := err.(*InvalidError); err != nil && !ok {
+		e.ShowError(fmt.Errorf("config: %s", err))
+		return
+	}
+
+	c.Preferences = p
+
+	v := reflect.ValueOf(&c.Preferences).Elem()
+	for key, o := range c.Overrides {
+		o.Original = v.FieldByName(key).Interface()
+		v.FieldByName(key).Set(reflect.ValueOf(o.Value))
+		c.Overrides[key] = o
+	}
+
+	e.Input.Load(c)
+	e.FrameMs = uint32(1000 / c.MaxFps)
+
+	if err != nil {
+		e.ShowError(err)
+		return
+	}
+
+	e.ShowMessage("config reloaded")
+}
+
+// Reloads tuning, only live values are applied
+func (w *Watcher) ReloadTuning() {
+	e := w.Engine
+	c := e.Cfg
+
+	js, err := ioutil.ReadFile(c.Tuning.File())
+	if err != nil {
+		e.ShowError(err)
+		return
+	}
+
+	t := Tuning{}
+	t.Default()
+
+	err = t.Decode(js)
+	if _, ok := err.(*InvalidError); err != nil && !ok {
+		e.ShowError(fmt.Errorf("tuning: %s", err))
+		return
+	}
+
+	// Overrides from command line are kept
+	v := reflect.ValueOf(&t).Elem()
+	for key, o := range c.TuningOverrides {
+		v.FieldByName(key).Set(reflect.ValueOf(o.Value))
+	}
+
+	restart := w.ApplyTuning(&t)
+
+	if err != nil {
+		e.ShowError(err)
+		return
+	}
+
+	if len(restart) > 0 {
+		e.ShowError(fmt.Errorf("tuning reloaded, restart to apply %s", strings.Join(restart, ", ")))
+		return
+	}
+
+	e.ShowMessage("tuning reloaded")
+}
+
+// Applies live values of tuning, returns changed values that need restart
+func (w *Watcher) ApplyTuning(t *Tuning) (restart []string) {
+	e := w.Engine
+	c := e.Cfg
+
+	live := make(map[string]bool)
+	for _, name := range LiveTuning {
+		live[name] = true
+	}
+
+	changed := false
+
+	// Slowdown powup keeps speed lowered
+	if c.Speed == c.GameSpeed {
+		c.Speed = t.GameSpeed
+	}
+
+	dst := reflect.ValueOf(&c.Tuning).Elem()
+	src := reflect.ValueOf(t).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if reflect.DeepEqual(dst.Field(i).Interface(), src.Field(i).Interface()) {
+			continue
+		}
+
+		name := dst.Type().Field(i).Name
+		if live[name] {
+			dst.Field(i).Set(src.Field(i))
+			changed = true
+		} else {
+			restart = append(restart, name)
+		}
+	}
+
+	if changed {
+		e.TuningReloads++
+	}
+
+	return
+}
+
+// Saves config, own changes are not reloaded
+func (e *Engine) SaveConfig() {
+	e.Cfg.Save()
+
+	if e.Watcher != nil {
+		e.Watcher.ConfigTime = modTime(e.Cfg.Preferences.File())
+	}
+}
+
+// Shows message on screen
+func (e *Engine) ShowMessage(text string) {
+	e.Message = text
+	e.MessageError = false
+	e.MessageTicks = e.UIClock.Ticks()
+}
+
+// Shows error on screen
+func (e *Engine) ShowError(err error) {
+	e.Message = err.Error()
+	e.MessageError = true
+	e.MessageTicks = e.UIClock.Ticks()
+}
+
+// Returns file modification time, zero if file doesn't exist
+func modTime(file string) time.Time {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return fi.ModTime()
+}
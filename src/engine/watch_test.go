@@ -0,0 +1,67 @@
+// VoV engine
+package engine
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+// Returns headless engine watching temporary config files
+func newWatchEngine(t *testing.T) *Engine {
+	tempConfigFiles(t)
+
+	e := NewEngine(NewConfig())
+	if err := e.InitHeadless(640, 480); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Watch()
+	return e
+}
+
+func TestReloadTuning(t *testing.T) {
+	e := newWatchEngine(t)
+	e.Cfg.SetAll([]string{"Bounciness=0.5"})
+
+	js := []byte(`{"BarrierSpeed": 3, "Bounciness": 0.9, "NRocks": 10}`)
+	if err := ioutil.WriteFile(TuningFile, js, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Live values are applied at once
+	e.Watcher.ReloadTuning()
+
+	if e.Cfg.BarrierSpeed != 3 || e.TuningReloads != 1 {
+		t.Errorf("barrier speed %g reloads %d, want 3 1", e.Cfg.BarrierSpeed, e.TuningReloads)
+	}
+
+	// Override is kept, restart values are not applied
+	if e.Cfg.Bounciness != 0.5 {
+		t.Errorf("bounciness %g, want override 0.5", e.Cfg.Bounciness)
+	}
+
+	if e.Cfg.NRocks == 10 || !e.MessageError {
+		t.Errorf("rocks %d message %q, want restart error", e.Cfg.NRocks, e.Message)
+	}
+
+	// Unchanged live values are not counted
+	e.Watcher.ReloadTuning()
+
+	if e.TuningReloads != 1 {
+		t.Errorf("reloads %d after same tuning, want 1", e.TuningReloads)
+	}
+}
+
+func TestSaveConfig(t *testing.T) {
+	e := newWatchEngine(t)
+
+	e.Cfg.ShowFps = true
+	e.SaveConfig()
+
+	e.Advance(WATCH_INTERVAL)
+	e.Watcher.Check()
+
+	if e.Message != "" {
+		t.Errorf("message %q after own save, want none", e.Message)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Command line flags, defaults are taken from VOV_* environment variables
+type Flags struct {
+	DataDir string
+	Config  string
+	Tuning  string
+	State   string
+
+	Seed       int64
+	Fullscreen bool
+	Mute       bool
+	Watch      bool
+	Width      int
+	Height     int
+	MaxFps     int
+
+	// Config fields as Key=Value
+	Set settings
+}
+
+// List of Key=Value settings, flag can be repeated
+type settings []string
+
+// Returns settings as string
+func (s *settings) String() string {
+	return strings.Join(*s, ";")
+}
+
+// Adds setting
+func (s *settings) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected Key=Value, got %q", value)
+	}
+
+	*s = append(*s, value)
+	return nil
+}
+
+// Parses command line flags
+func ParseFlags(args []string) (f *Flags, err error) {
+	f = &Flags{}
+
+	fs := flag.NewFlagSet("vov", flag.ContinueOnError)
+
+	fs.StringVar(&f.DataDir, "data-dir", os.Getenv("VOV_DATA_DIR"), "Data directory, env VOV_DATA_DIR")
+	fs.StringVar(&f.Config, "config", os.Getenv("VOV_CONFIG"), "Config file, env VOV_CONFIG")
+	fs.StringVar(&f.Tuning, "tuning", os.Getenv("VOV_TUNING"), "Tuning profile, env VOV_TUNING")
+	fs.StringVar(&f.State, "state", os.Getenv("VOV_STATE"), "Initial state, menu, game, daily, options or credits, env VOV_STATE")
+
+	var seed, width, height, maxFps int
+	var fullscreen, mute, watch bool
+
+	if seed, err = envInt("VOV_SEED"); err != nil {
+		return
+	}
+	if width, err = envInt("VOV_WIDTH"); err != nil {
+		return
+	}
+	if height, err = envInt("VOV_HEIGHT"); err != nil {
+		return
+	}
+	if maxFps, err = envInt("VOV_MAX_FPS"); err != nil {
+		return
+	}
+	if fullscreen, err = envBool("VOV_FULLSCREEN"); err != nil {
+		return
+	}
+	if mute, err = envBool("VOV_MUTE"); err != nil {
+		return
+	}
+	if watch, err = envBool("VOV_WATCH"); err != nil {
+		return
+	}
+
+	fs.Int64Var(&f.Seed, "seed", int64(seed), "Random seed, 0 for random, env VOV_SEED")
+	fs.BoolVar(&f.Fullscreen, "fullscreen", fullscreen, "Start in fullscreen, env VOV_FULLSCREEN")
+	fs.BoolVar(&f.Mute, "mute", mute, "Disable music and sounds, env VOV_MUTE")
+	fs.BoolVar(&f.Watch, "watch", watch, "Reload changed config and tuning files, env VOV_WATCH")
+	fs.IntVar(&f.Width, "width", width, "Window width, height follows display aspect ratio if not set, env VOV_WIDTH")
+	fs.IntVar(&f.Height, "height", height, "Window height, width follows display aspect ratio if not set, env VOV_HEIGHT")
+	fs.IntVar(&f.MaxFps, "max-fps", maxFps, "Maximum frames per second, env VOV_MAX_FPS")
+
+	// Environment settings are separated with semicolon
+	for _, s := range strings.Split(os.Getenv("VOV_SET"), ";") {
+		if s = strings.TrimSpace(s); s != "" {
+			if err = f.Set.Set(s); err != nil {
+				return
+			}
+		}
+	}
+	fs.Var(&f.Set, "set", "Set config field, Key=Value, can be repeated, env VOV_SET separated with semicolon")
+
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+
+	switch f.State {
+	case "", "menu", "game", "daily", "options", "credits":
+	default:
+		err = fmt.Errorf("unknown state %q", f.State)
+	}
+
+	return
+}
+
+// Returns config settings of flags, explicit settings are last
+func (f *Flags) Settings() (s []string) {
+	if f.Mute {
+		s = append(s, "MusicEnabled=false", "SoundsEnabled=false")
+	}
+
+	if f.MaxFps > 0 {
+		s = append(s, fmt.Sprintf("MaxFps=%d", f.MaxFps))
+	}
+
+	// Missing dimension follows display aspect ratio
+	if f.Width > 0 {
+		s = append(s, fmt.Sprintf("WinWidth=%d", f.Width))
+	}
+
+	if f.Height > 0 {
+		s = append(s, fmt.Sprintf("WinHeight=%d", f.Height))
+	}
+
+	if f.Watch {
+		s = append(s, "WatchConfig=true")
+	}
+
+	if f.Seed != 0 {
+		s = append(s, fmt.Sprintf("Seed=%d", f.Seed))
+	}
+
+	s = append(s, f.Set...)
+	return
+}
+
+// Returns integer environment variable, 0 if not set
+func envInt(name string) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s", name, err)
+	}
+
+	return i, nil
+}
+
+// Returns boolean environment variable, false if not set
+func envBool(name string) (bool, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: %s", name, err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,65 @@
+// VoV game
+package game
+
+// Gameplay event type
+type EventType int
+
+// Gameplay event types
+const (
+	// Ship collided with rock
+	RockHit EventType = iota
+	// Ship bounced from rock
+	ShipBounced
+	// Ship lost life
+	ShipKilled
+	// Rock was destroyed
+	RockDestroyed
+	// Ship picked up powup
+	PowupCollected
+	// Game state changed
+	StateChanged
+)
+
+// Gameplay event
+type Event struct {
+	Type EventType
+
+	// Ship state, powup state for PowupCollected, game state for StateChanged
+	State int
+
+	// Game state before StateChanged
+	LastState int
+
+	// Rock involved in event
+	Rock *Sprite
+
+	// Rock destroyed by ship, otherwise by dots
+	ByShip bool
+}
+
+// Event handler
+type Handler func(ev Event)
+
+// Publish/subscribe event bus
+type EventBus struct {
+	Handlers map[EventType][]Handler
+}
+
+// Returns new event bus
+func NewEventBus() (b *EventBus) {
+	b = &EventBus{}
+	b.Handlers = make(map[EventType][]Handler)
+	return
+}
+
+// Subscribes handler to event type
+func (b *EventBus) Subscribe(typ EventType, h Handler) {
+	b.Handlers[typ] = append(b.Handlers[typ], h)
+}
+
+// Publishes event to subscribers, in order of subscription
+func (b *EventBus) Publish(ev Event) {
+	for _, h := range b.Handlers[ev.Type] {
+		h(ev)
+	}
+}
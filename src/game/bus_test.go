@@ -0,0 +1,44 @@
+// VoV game
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEventBus(t *testing.T) {
+	b := NewEventBus()
+
+	var got []string
+	b.Subscribe(RockHit, func(ev Event) { got = append(got, "hit 1") })
+	b.Subscribe(ShipKilled, func(ev Event) { got = append(got, "killed") })
+	b.Subscribe(RockHit, func(ev Event) { got = append(got, "hit 2") })
+	b.Subscribe(StateChanged, func(ev Event) {
+		got = append(got, "state")
+		if ev.State != GameOver || ev.LastState != GamePlay {
+			t.Errorf("state event %+v, want play to over", ev)
+		}
+	})
+
+	// Subscribers get their types only, in order of subscription
+	b.Publish(Event{Type: RockHit})
+	b.Publish(Event{Type: PowupCollected})
+	b.Publish(Event{Type: StateChanged, State: GameOver, LastState: GamePlay})
+	b.Publish(Event{Type: ShipKilled})
+
+	if want := []string{"hit 1", "hit 2", "state", "killed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("handled %v, want %v", got, want)
+	}
+}
+
+func TestGameSubscribers(t *testing.T) {
+	e, r := newTestEngine(t, 1)
+	g := NewGame(e, r)
+
+	// Bounce rumbles, kill plays sound
+	for _, typ := range []EventType{ShipBounced, ShipKilled} {
+		if len(g.Events.Handlers[typ]) == 0 {
+			t.Errorf("no handlers for event type %d", typ)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// VoV game
+package game
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/gen2brain/vov/src/engine"
+)
+
+// Confirm structure, dialog drawn over previous state
+type Confirm struct {
+	Engine   *engine.Engine
+	Resource *engine.Resource
+
+	// Question text
+	Text string
+
+	// Called when confirmed
+	OnConfirm func()
+}
+
+// Returns new confirmation dialog
+func NewConfirm(e *engine.Engine, r *engine.Resource, text string, confirm func()) (c *Confirm) {
+	c = &Confirm{}
+	c.Engine = e
+	c.Resource = r
+	c.Text = text
+	c.OnConfirm = confirm
+
+	return
+}
+
+// Initializes confirm state
+func (c *Confirm) OnInit() bool {
+	return true
+}
+
+// Quits confirm state
+func (c *Confirm) OnQuit() bool {
+	return true
+}
+
+// Returns state string
+func (c *Confirm) String() string {
+	return "Confirm"
+}
+
+// States below dialog are not updated
+func (c *Confirm) Frozen() bool {
+	return true
+}
+
+// Handles input events
+func (c *Confirm) HandleEvents() {
+	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+		c.HandleEvent(event)
+	}
+}
+
+// Handles input event
+func (c *Confirm) HandleEvent(event sdl.Event) {
+	// Handle mapped actions
+	if a, pressed := c.Engine.Input.Actions(event); a != 0 {
+		if !pressed {
+			return
+		}
+
+		if a&engine.ACTION_CONFIRM != 0 {
+			c.Confirm()
+		} else if a&engine.ACTION_BACK != 0 {
+			c.Cancel()
+		}
+
+		return
+	}
+
+	switch t := event.(type) {
+	case *sdl.QuitEvent:
+		// Handle quit event
+		c.Engine.Quit()
+
+	case *sdl.KeyDownEvent:
+		switch t.Keysym.Scancode {
+		case sdl.SCANCODE_Y:
+			c.Confirm()
+		case sdl.SCANCODE_N:
+			c.Cancel()
+		}
+
+	case *sdl.TouchFingerEvent:
+		// Confirm on touch in left half, cancel in right half
+		if t.Type == sdl.FINGERDOWN {
+			if t.X < 0.5 {
+				c.Confirm()
+			} else {
+				c.Cancel()
+			}
+		}
+	}
+}
+
+// Confirms dialog
+func (c *Confirm) Confirm() {
+	c.Resource.PlaySound(c.Resource.SoundClick, -1, 0)
+
+	c.Engine.State.Pop()
+	if c.OnConfirm != nil {
+		c.OnConfirm()
+	}
+}
+
+// Cancels dialog
+func (c *Confirm) Cancel() {
+	c.Resource.PlaySound(c.Resource.SoundClick, -1, 0)
+	c.Engine.State.Pop()
+}
+
+// Updates confirm
+func (c *Confirm) Update() {
+}
+
+// Draws confirm
+func (c *Confirm) Draw() {
+	// Dim states below
+	c.Engine.Renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	c.Engine.Renderer.SetDrawColor(0, 0, 0, 160)
+	c.Engine.Renderer.FillRect(nil)
+
+	w, h, _ := c.Resource.FontMedium.SizeUTF8(c.Text)
+	x := int32(c.Engine.Cfg.WinWidth-float64(w)) / 2
+	y := int32(c.Engine.Cfg.WinHeight-float64(h)) / 2
+
+	c.Resource.DrawText(c.Text, x, y, engine.FONT_MEDIUM)
+
+	w, _, _ = c.Resource.FontSmall.SizeUTF8("Y / N")
+	c.Resource.DrawText("Y / N", int32(c.Engine.Cfg.WinWidth-float64(w))/2, y+int32(h)+20, engine.FONT_SMALL)
+}
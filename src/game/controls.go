@@ -0,0 +1,383 @@
+// VoV game
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/gen2brain/vov/src/engine"
+)
+
+// Controls structure, lists actions and lets player rebind them
+type Controls struct {
+	Engine   *engine.Engine
+	Resource *engine.Resource
+
+	Fog  *Fog
+	Dust *Dust
+
+	// Selected row, last row resets bindings
+	Selected int
+
+	// Waiting for new key or button
+	Waiting bool
+	// Start of waiting (UI clock milliseconds)
+	WaitTicks uint32
+
+	// Actions that gave their binding to last rebound action
+	Swapped []engine.Action
+}
+
+// How long to wait for new key or button (milliseconds)
+const bindTimeout = 5000
+
+// Returns new controls
+func NewControls(e *engine.Engine, r *engine.Resource) (c *Controls) {
+	c = &Controls{}
+	c.Engine = e
+	c.Resource = r
+
+	rnd := NewRandom(time.Now().UTC().UnixNano())
+
+	c.Fog = NewFog(e, r, rnd)
+	c.Dust = NewDust(e, rnd)
+
+	return
+}
+
+// Initializes state
+func (c *Controls) OnInit() bool {
+	c.Fog.Init()
+	c.Dust.Init()
+
+	c.Engine.Cfg.MergeBindings()
+
+	c.Selected = 0
+	c.Waiting = false
+	c.Swapped = nil
+
+	if !c.Resource.PlayingMusic(c.Resource.MusicMenu) {
+		c.Resource.PlayMusic(c.Resource.MusicMenu, -1)
+	}
+
+	return true
+}
+
+// Quits state
+func (c *Controls) OnQuit() bool {
+	c.Engine.SaveConfig()
+	return true
+}
+
+// Returns state string
+func (c *Controls) String() string {
+	return "Controls"
+}
+
+// Handles input events
+func (c *Controls) HandleEvents() {
+	if c.Engine.Clock.Paused {
+		event := sdl.WaitEvent()
+		if event != nil {
+			c.HandleEvent(event)
+		}
+	} else {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			c.HandleEvent(event)
+		}
+	}
+}
+
+// Handles input event
+func (c *Controls) HandleEvent(event sdl.Event) {
+	if _, ok := event.(*sdl.QuitEvent); ok {
+		// Handle quit event
+		c.Engine.Quit()
+		return
+	}
+
+	// Bind next key or button to selected action
+	if c.Waiting {
+		c.HandleBind(event)
+		return
+	}
+
+	// Handle mapped actions
+	if a, pressed := c.Engine.Input.Actions(event); a != 0 {
+		if pressed {
+			c.HandleAction(a)
+		}
+		return
+	}
+
+	switch t := event.(type) {
+	case *sdl.MouseButtonEvent:
+		// Select row on mouse left button
+		if t.Type == sdl.MOUSEBUTTONDOWN && t.Button == sdl.BUTTON_LEFT {
+			if row := c.Row(t.Y); row != -1 {
+				c.Selected = row
+				c.Activate()
+			}
+		}
+
+	case *sdl.TouchFingerEvent:
+		// Select row on touch
+		if t.Type == sdl.FINGERDOWN {
+			if row := c.Row(int32(float64(t.Y) * c.Engine.Cfg.WinHeight)); row != -1 {
+				c.Selected = row
+				c.Activate()
+			}
+		}
+
+	case *sdl.ControllerDeviceEvent:
+		// Initialize/Remove controller
+		if t.Type == sdl.CONTROLLERDEVICEADDED {
+			c.Engine.Controller = sdl.GameControllerOpen(int(t.Which))
+			if c.Engine.Cfg.HapticEnabled {
+				c.Engine.SetHaptic()
+			}
+		} else if t.Type == sdl.CONTROLLERDEVICEREMOVED {
+			c.Engine.CloseController()
+		}
+
+	default:
+		break
+	}
+}
+
+// Handles input action
+func (c *Controls) HandleAction(a engine.Action) {
+	switch {
+	case a&engine.ACTION_TOGGLE_FULLSCREEN != 0:
+		c.Engine.Fullscreen()
+
+	case a&engine.ACTION_BACK != 0:
+		// Change state on back
+		c.Resource.PlaySound(c.Resource.SoundClick, -1, 0)
+		c.Engine.State.Change(NewOptions(c.Engine, c.Resource))
+
+	case a&engine.ACTION_CONFIRM != 0:
+		c.Activate()
+
+	case a&engine.ACTION_MENU_UP != 0:
+		c.Selected = (c.Selected - 1 + c.Rows()) % c.Rows()
+		c.Swapped = nil
+
+	case a&engine.ACTION_MENU_DOWN != 0:
+		c.Selected = (c.Selected + 1) % c.Rows()
+		c.Swapped = nil
+	}
+}
+
+// Handles event while waiting for new binding, any key can be bound.
+// Click, touch or timeout cancels
+func (c *Controls) HandleBind(event sdl.Event) {
+	a := engine.Actions[c.Selected]
+
+	switch t := event.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat != 0 {
+			return
+		}
+
+		if key := sdl.GetScancodeName(t.Keysym.Scancode); key != "" {
+			c.Bound(engine.Rebind(c.Engine.Cfg.KeyBindings, a, key))
+		}
+
+	case *sdl.ControllerButtonEvent:
+		if t.Type != sdl.CONTROLLERBUTTONDOWN {
+			return
+		}
+
+		if button := sdl.GameControllerGetStringForButton(int(t.Button)); button != "" {
+			c.Bound(engine.Rebind(c.Engine.Cfg.ButtonBindings, a, button))
+		}
+
+	case *sdl.MouseButtonEvent:
+		if t.Type == sdl.MOUSEBUTTONDOWN {
+			c.Cancel()
+		}
+
+	case *sdl.TouchFingerEvent:
+		if t.Type == sdl.FINGERDOWN {
+			c.Cancel()
+		}
+	}
+}
+
+// Reloads input after binding changed
+func (c *Controls) Bound(swapped []engine.Action) {
+	c.Resource.PlaySound(c.Resource.SoundClick, -1, 0)
+	c.Engine.Input.Load(c.Engine.Cfg)
+	c.Waiting = false
+	c.Swapped = swapped
+}
+
+// Stops waiting for new binding
+func (c *Controls) Cancel() {
+	c.Resource.PlaySound(c.Resource.SoundClick, -1, 0)
+	c.Waiting = false
+}
+
+// Starts rebinding selected action, or resets bindings
+func (c *Controls) Activate() {
+	c.Resource.PlaySound(c.Resource.SoundClick, -1, 0)
+	c.Swapped = nil
+
+	if c.Selected == len(engine.Actions) {
+		c.Engine.Cfg.ResetBindings()
+		c.Engine.Input.Load(c.Engine.Cfg)
+		return
+	}
+
+	c.Waiting = true
+	c.WaitTicks = c.Engine.UIClock.Ticks()
+}
+
+// Returns number of rows
+func (c *Controls) Rows() int {
+	return len(engine.Actions) + 1
+}
+
+// Returns height of row
+func (c *Controls) RowHeight() int32 {
+	h := int32(c.Engine.Cfg.WinHeight-160) / int32(c.Rows())
+	if h > 30 {
+		h = 30
+	}
+
+	return h
+}
+
+// Returns y position of row
+func (c *Controls) RowY(row int) int32 {
+	return 100 + int32(row)*c.RowHeight()
+}
+
+// Returns row at y position, -1 if none
+func (c *Controls) Row(y int32) int {
+	if y < c.RowY(0) {
+		return -1
+	}
+
+	row := int((y - c.RowY(0)) / c.RowHeight())
+	if row >= c.Rows() {
+		return -1
+	}
+
+	return row
+}
+
+// Updates controls
+func (c *Controls) Update() {
+	// Don't update if timer is paused
+	if c.Engine.Clock.Paused {
+		return
+	}
+
+	// Stop waiting for binding after timeout
+	if c.Waiting && c.Engine.UIClock.Ticks()-c.WaitTicks > bindTimeout {
+		c.Waiting = false
+	}
+
+	// Scrolling
+	c.Engine.ScreenDX = c.Engine.Cfg.BarrierSpeed
+
+	// Update dust
+	c.Dust.Update()
+
+	// Update background
+	c.Fog.Update()
+}
+
+// Draws controls
+func (c *Controls) Draw() {
+	// Draw dust
+	c.Dust.Draw()
+
+	// Draw background
+	c.Fog.Draw()
+
+	w, _, _ := c.Resource.FontMedium.SizeUTF8("CONTROLS")
+	c.Resource.DrawText("CONTROLS", int32(c.Engine.Cfg.WinWidth-float64(w))/2, 40, engine.FONT_MEDIUM)
+
+	x := int32(c.Engine.Cfg.WinWidth * 0.15)
+	keyX := int32(c.Engine.Cfg.WinWidth * 0.45)
+	buttonX := int32(c.Engine.Cfg.WinWidth * 0.7)
+
+	c.Resource.DrawText("KEY", keyX, c.RowY(0)-c.RowHeight(), engine.FONT_SMALL_RED)
+	c.Resource.DrawText("BUTTON", buttonX, c.RowY(0)-c.RowHeight(), engine.FONT_SMALL_RED)
+
+	for i, a := range engine.Actions {
+		y := c.RowY(i)
+
+		font := engine.FONT_SMALL
+		if len(c.Conflicts(a)) > 0 {
+			font = engine.FONT_SMALL_RED
+		}
+
+		keys := strings.Join(c.Engine.Cfg.KeyBindings[a.String()], ", ")
+		buttons := strings.Join(c.Engine.Cfg.ButtonBindings[a.String()], ", ")
+
+		if i == c.Selected && c.Waiting {
+			keys, buttons = "?", "?"
+		}
+
+		c.Resource.DrawText(strings.ToUpper(a.String()), x, y, font)
+		c.Resource.DrawText(strings.ToUpper(keys), keyX, y, font)
+		c.Resource.DrawText(strings.ToUpper(buttons), buttonX, y, font)
+	}
+
+	c.Resource.DrawText("RESET TO DEFAULTS", x, c.RowY(len(engine.Actions)), engine.FONT_SMALL)
+	c.Resource.DrawText(">", x-25, c.RowY(c.Selected), engine.FONT_SMALL)
+
+	// Draw help line
+	text := "PRESS ENTER TO REBIND"
+	if c.Waiting {
+		left := (bindTimeout - int(c.Engine.UIClock.Ticks()-c.WaitTicks) + 999) / 1000
+		text = fmt.Sprintf("PRESS NEW KEY OR BUTTON, CLICK TO CANCEL (%d)", left)
+	} else if c.Selected == len(engine.Actions) {
+		text = "PRESS ENTER TO RESET ALL BINDINGS"
+	} else if len(c.Swapped) > 0 {
+		text = "SWAPPED WITH " + actionList(c.Swapped)
+	} else if conflicts := c.Conflicts(engine.Actions[c.Selected]); len(conflicts) > 0 {
+		text = "CONFLICTS WITH " + actionList(conflicts)
+	}
+
+	w, _, _ = c.Resource.FontSmall.SizeUTF8(text)
+	c.Resource.DrawText(text, int32(c.Engine.Cfg.WinWidth-float64(w))/2, c.RowY(c.Rows())+20, engine.FONT_SMALL)
+}
+
+// Returns upper case names of actions
+func actionList(actions []engine.Action) string {
+	names := make([]string, 0)
+	for _, a := range actions {
+		names = append(names, strings.ToUpper(a.String()))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Returns actions sharing key or button with action
+func (c *Controls) Conflicts(a engine.Action) []engine.Action {
+	conflicts := engine.Conflicts(c.Engine.Cfg.KeyBindings, a)
+
+	for _, b := range engine.Conflicts(c.Engine.Cfg.ButtonBindings, a) {
+		found := false
+		for _, x := range conflicts {
+			if x == b {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			conflicts = append(conflicts, b)
+		}
+	}
+
+	return conflicts
+}
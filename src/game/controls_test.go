@@ -0,0 +1,101 @@
+// VoV game
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/gen2brain/vov/src/engine"
+)
+
+// Returns row of action
+func actionRow(a engine.Action) int {
+	for i, b := range engine.Actions {
+		if a == b {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns key down event of key name
+func keyDown(name string) *sdl.KeyDownEvent {
+	return &sdl.KeyDownEvent{Type: sdl.KEYDOWN, Keysym: sdl.Keysym{Scancode: sdl.GetScancodeFromName(name)}}
+}
+
+func TestControlsSwap(t *testing.T) {
+	e, r := newTestEngine(t, 1)
+
+	c := NewControls(e, r)
+	c.OnInit()
+
+	// Binding key of other action swaps bindings
+	c.Selected = actionRow(engine.ACTION_CONFIRM)
+	c.Activate()
+	c.HandleEvent(keyDown("Space"))
+
+	if c.Waiting || !reflect.DeepEqual(c.Swapped, []engine.Action{engine.ACTION_PAUSE}) {
+		t.Errorf("waiting %v swapped %v, want done with Pause", c.Waiting, c.Swapped)
+	}
+
+	if a, _ := e.Input.Actions(keyDown("Space")); a != engine.ACTION_CONFIRM {
+		t.Errorf("Space action %s, want Confirm", a)
+	}
+
+	if a, _ := e.Input.Actions(keyDown("Return")); a != engine.ACTION_PAUSE {
+		t.Errorf("Return action %s, want Pause", a)
+	}
+
+	// Click cancels
+	c.Activate()
+	c.HandleEvent(&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN, Button: sdl.BUTTON_LEFT})
+
+	if c.Waiting {
+		t.Error("still waiting after click")
+	}
+
+	// Timeout cancels
+	c.Activate()
+	e.Advance(bindTimeout + 1)
+	c.Update()
+
+	if c.Waiting {
+		t.Error("still waiting after timeout")
+	}
+
+	// Last row resets bindings
+	c.Selected = len(engine.Actions)
+	c.Activate()
+
+	if !reflect.DeepEqual(e.Cfg.KeyBindings, engine.DefaultKeyBindings()) {
+		t.Errorf("bindings %v after reset, want defaults", e.Cfg.KeyBindings)
+	}
+}
+
+func TestControlsSave(t *testing.T) {
+	e, r := newTestEngine(t, 1)
+
+	c := NewControls(e, r)
+	c.OnInit()
+
+	c.Selected = actionRow(engine.ACTION_EXPORT_GIF)
+	c.Activate()
+	c.HandleEvent(keyDown("F5"))
+	c.OnQuit()
+
+	p := engine.Preferences{}
+	p.Default()
+	if _, err := p.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"F5"}; !reflect.DeepEqual(p.KeyBindings["ExportGif"], want) {
+		t.Errorf("saved ExportGif %v, want %v", p.KeyBindings["ExportGif"], want)
+	}
+
+	if want := e.Cfg.KeyBindings; !reflect.DeepEqual(p.KeyBindings, want) {
+		t.Errorf("saved bindings %v, want %v", p.KeyBindings, want)
+	}
+}
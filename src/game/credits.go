@@ -2,8 +2,9 @@
 package game
 
 import (
+	"time"
+
 	"github.com/veandco/go-sdl2/sdl"
-	"github.com/veandco/go-sdl2/sdl_mixer"
 
 	"github.com/gen2brain/vov/src/engine"
 )
@@ -43,8 +44,10 @@ func NewCredits(e *engine.Engine, r *engine.Resource) (c *Credits) {
 	c.Engine = e
 	c.Resource = r
 
-	c.Fog = NewFog(e, r)
-	c.Dust = NewDust(e)
+	rnd := NewRandom(time.Now().UTC().UnixNano())
+
+	c.Fog = NewFog(e, r, rnd)
+	c.Dust = NewDust(e, rnd)
 
 	return
 }
@@ -74,7 +77,7 @@ func (c *Credits) OnInit() bool {
 		c.Credits[i].Name.Y = c.Engine.Cfg.WinHeight + c.Credits[i].Role.Height + c.Credits[i].Name.Height + float64(i*120)
 	}
 
-	if !mix.PlayingMusic() {
+	if !c.Resource.PlayingMusic(c.Resource.MusicMenu) {
 		c.Resource.PlayMusic(c.Resource.MusicMenu, -1)
 	}
 
@@ -93,7 +96,7 @@ func (c *Credits) String() string {
 
 // Handles input events
 func (c *Credits) HandleEvents() {
-	if engine.Paused {
+	if c.Engine.Clock.Paused {
 		event := sdl.WaitEvent()
 		if event != nil {
 			c.HandleEvent(event)
@@ -107,21 +110,28 @@ func (c *Credits) HandleEvents() {
 
 // Handles input event
 func (c *Credits) HandleEvent(event sdl.Event) {
-	switch t := event.(type) {
-	case *sdl.QuitEvent:
-		// Handle quit event
-		c.Engine.Quit()
+	// Handle mapped actions
+	if a, pressed := c.Engine.Input.Actions(event); a != 0 {
+		if !pressed {
+			return
+		}
 
-	case *sdl.KeyDownEvent:
-		if t.Keysym.Scancode == sdl.SCANCODE_ESCAPE || t.Keysym.Scancode == sdl.SCANCODE_AC_BACK {
-			// Change state on back/escape
+		if a&engine.ACTION_TOGGLE_FULLSCREEN != 0 {
+			c.Engine.Fullscreen()
+		} else if a&engine.ACTION_BACK != 0 {
+			// Change state on back
 			c.Resource.PlaySound(c.Resource.SoundClick, -1, 0)
 			c.Engine.State.Change(NewMenu(c.Engine, c.Resource))
-		} else if (t.Keysym.Mod&sdl.KMOD_ALT != 0 && t.Keysym.Scancode == sdl.SCANCODE_RETURN) || t.Keysym.Scancode == sdl.SCANCODE_F11 {
-			// Fullscreen
-			c.Engine.Fullscreen()
 		}
 
+		return
+	}
+
+	switch t := event.(type) {
+	case *sdl.QuitEvent:
+		// Handle quit event
+		c.Engine.Quit()
+
 	case *sdl.ControllerDeviceEvent:
 		// Initialize/Remove controller
 		if t.Type == sdl.CONTROLLERDEVICEADDED {
@@ -133,15 +143,6 @@ func (c *Credits) HandleEvent(event sdl.Event) {
 			c.Engine.CloseController()
 		}
 
-	case *sdl.ControllerButtonEvent:
-		// Controller buttons
-		if t.Type == sdl.CONTROLLERBUTTONDOWN {
-			if t.Button == sdl.CONTROLLER_BUTTON_B || t.Button == sdl.CONTROLLER_BUTTON_BACK {
-				c.Resource.PlaySound(c.Resource.SoundClick, -1, 0)
-				c.Engine.State.Change(NewMenu(c.Engine, c.Resource))
-			}
-		}
-
 	case *sdl.TouchFingerEvent:
 		// Pause scroll on touch
 		if t.Type == sdl.FINGERDOWN {
@@ -166,7 +167,7 @@ func (c *Credits) HandleEvent(event sdl.Event) {
 // Updates credits
 func (c *Credits) Update() {
 	// Don't update if timer is paused
-	if engine.Paused {
+	if c.Engine.Clock.Paused {
 		return
 	}
 
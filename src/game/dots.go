@@ -108,8 +108,8 @@ func (d *Dot) Collisions() {
 
 				if g.Rocks.Rocks[i].Life < 0 {
 					// Kill rock if out of life
-					g.Resource.PlaySound(g.Resource.SoundExplosion2, -1, 0)
 					g.Rocks.Rocks[i].Kill()
+					g.Events.Publish(Event{Type: RockDestroyed, State: g.Ship.State, Rock: g.Rocks.Rocks[i]})
 
 					// Bang dots
 					g.Dots.NewBangDots(g.Rocks.Rocks[i])
@@ -166,7 +166,7 @@ func (d *Dots) Init() {
 	for i := 0; i < d.Cfg.NShipDotsArray; i++ {
 		points := make([]sdl.Point, d.Cfg.MaxShipDots/d.Cfg.NShipDotsArray)
 
-		idx := urnd() % uint32(len(d.HeatColors))
+		idx := d.Game.Rand.urnd() % uint32(len(d.HeatColors))
 		color := d.HeatColors[idx]
 
 		for n := 0; n < d.Cfg.MaxShipDots/d.Cfg.NShipDotsArray; n++ {
@@ -189,7 +189,7 @@ func (d *Dots) Init() {
 	for i := 0; i < d.Cfg.NBangDotsArray; i++ {
 		points := make([]sdl.Point, d.Cfg.MaxBangDots/d.Cfg.NBangDotsArray)
 
-		idx := urnd() % uint32(len(d.HeatColors))
+		idx := d.Game.Rand.urnd() % uint32(len(d.HeatColors))
 		color := d.HeatColors[idx]
 
 		for n := 0; n < d.Cfg.MaxBangDots/d.Cfg.NBangDotsArray; n++ {
@@ -246,18 +246,23 @@ func (d *Dots) InitColors() {
 // Generates new ship engine dots
 func (d *Dots) NewShipDots() {
 
-	n := d.Cfg.EngineDots
+	n := d.Cfg.ShipDots
 
 	for dir := 0; dir < 4; dir++ {
 		if d.Game.Ship.Jets&(1<<uint(dir)) == 0 {
 			continue
 		}
 
-		for i := 0; i < n; i++ {
+		// Number of dots is proportional to thrust
+		count := int(float64(n) * d.Game.Ship.JetThrust(dir))
+
+		for i := 0; i < n && count > 0; i++ {
 
 			if !d.ShipDots[i].Active {
-				a := frnd()*math.Pi + float64(dir-1)*(math.Pi/2) // angle
-				r := math.Sin(frnd() * math.Pi)                  // random length
+				count--
+
+				a := d.Game.Rand.frnd()*math.Pi + float64(dir-1)*(math.Pi/2) // angle
+				r := math.Sin(d.Game.Rand.frnd() * math.Pi)                  // random length
 
 				dx := r * math.Cos(a)
 				dy := r * -math.Sin(a) // Screen y is "backwards"
@@ -265,15 +270,15 @@ func (d *Dots) NewShipDots() {
 				d.ShipDots[i].Decay = 3.5
 
 				// Dot was created at a random time during the time span
-				time := frnd() * d.Game.Engine.TFrame // This is how long ago
+				time := d.Game.Rand.frnd() * d.Game.Engine.TFrame // This is how long ago
 
 				// Calculate how fast the ship was going when this engine dot was created (as if it had a smooth acceleration).
 				// This is used in determining the velocity of the dots, but not their starting location.
-				accelh := float64((d.Game.Ship.Jets>>2)&1) - float64(d.Game.Ship.Jets&1)
+				accelh := d.Game.Ship.ThrustX
 				accelh *= d.Cfg.ThrusterStrength * time
 				pastShipDX := d.Game.Ship.DX - accelh
 
-				accelv := float64((d.Game.Ship.Jets>>1)&1) - float64((d.Game.Ship.Jets>>3)&1)
+				accelv := d.Game.Ship.ThrustY
 				accelv *= d.Cfg.ThrusterStrength * time
 				pastShipDY := d.Game.Ship.DY - accelv
 
@@ -309,9 +314,9 @@ func (d *Dots) NewShipBangDots() {
 	for i := 0; i < n; i++ {
 		for y := 0; y < int(d.Game.Ship.Height); y++ {
 			for x := 0; x < int(d.Game.Ship.Width); x++ {
-				theta := frnd() * math.Pi * 2
+				theta := d.Game.Rand.frnd() * math.Pi * 2
 
-				r := frnd()
+				r := d.Game.Rand.frnd()
 				r = 1 - r*r
 
 				d.BangDots[d.Bd].DX = 45*r*math.Cos(theta) + d.Game.Ship.DX
@@ -319,8 +324,8 @@ func (d *Dots) NewShipBangDots() {
 				d.BangDots[d.Bd].X = float64(x) + d.Game.Ship.X
 				d.BangDots[d.Bd].Y = float64(y) + d.Game.Ship.Y
 
-				d.BangDots[d.Bd].Mass = frnd() * 99
-				d.BangDots[d.Bd].Decay = frnd()*4.5 + 0.5
+				d.BangDots[d.Bd].Mass = d.Game.Rand.frnd() * 99
+				d.BangDots[d.Bd].Decay = d.Game.Rand.frnd()*4.5 + 0.5
 				d.BangDots[d.Bd].Active = true
 
 				d.Bd = (d.Bd + 1) % d.Cfg.MaxBangDots
@@ -335,9 +340,9 @@ func (d *Dots) NewBangDots(s *Sprite) {
 	for i := 0; i < n; i++ {
 		for y := 0; y < int(s.Height); y++ {
 			for x := 0; x < int(s.Width); x++ {
-				theta := frnd() * math.Pi * 2
+				theta := d.Game.Rand.frnd() * math.Pi * 2
 
-				r := frnd()
+				r := d.Game.Rand.frnd()
 				r = 1 - r*r
 
 				d.BangDots[d.Bd].DX = 45*r*math.Cos(theta) + s.DX
@@ -345,8 +350,8 @@ func (d *Dots) NewBangDots(s *Sprite) {
 				d.BangDots[d.Bd].X = float64(x) + s.X
 				d.BangDots[d.Bd].Y = float64(y) + s.Y
 
-				d.BangDots[d.Bd].Mass = frnd() * 99
-				d.BangDots[d.Bd].Decay = frnd()*1.5 + 0.5
+				d.BangDots[d.Bd].Mass = d.Game.Rand.frnd() * 99
+				d.BangDots[d.Bd].Decay = d.Game.Rand.frnd()*1.5 + 0.5
 				d.BangDots[d.Bd].Active = true
 
 				d.Bd = (d.Bd + 1) % d.Cfg.MaxBangDots
@@ -20,6 +20,7 @@ type DustMote struct {
 type Dust struct {
 	Engine *engine.Engine
 	Cfg    *engine.Config
+	Rand   *Random
 
 	// Dust motes
 	Motes map[sdl.Color][]*DustMote
@@ -29,10 +30,11 @@ type Dust struct {
 }
 
 // Returns new dust
-func NewDust(e *engine.Engine) (d *Dust) {
+func NewDust(e *engine.Engine, rnd *Random) (d *Dust) {
 	d = &Dust{}
 	d.Engine = e
 	d.Cfg = e.Cfg
+	d.Rand = rnd
 	return
 }
 
@@ -46,20 +48,20 @@ func (d *Dust) Init() {
 		motes := make([]*DustMote, d.Cfg.NDustMotes/d.Cfg.NDustArray)
 		points := make([]sdl.Point, d.Cfg.NDustMotes/d.Cfg.NDustArray)
 
-		z := d.Cfg.MaxDustDepth * math.Sqrt(frnd())
+		z := d.Cfg.MaxDustDepth * math.Sqrt(d.Rand.frnd())
 		c := (d.Cfg.MaxDustDepth - z) * 255.0 / d.Cfg.MaxDustDepth
 		color := sdl.Color{uint8(c), uint8(c), uint8(c), 255}
 
 		for n := 0; n < d.Cfg.NDustMotes/d.Cfg.NDustArray; n++ {
 			p := sdl.Point{}
-			p.X = int32(frnd() * (d.Cfg.WinWidth - 5))
-			p.Y = int32(frnd() * (d.Cfg.WinHeight - 5))
+			p.X = int32(d.Rand.frnd() * (d.Cfg.WinWidth - 5))
+			p.Y = int32(d.Rand.frnd() * (d.Cfg.WinHeight - 5))
 			points[n] = p
 
 			m := &DustMote{}
 			m.X = float64(p.X)
 			m.Y = float64(p.Y)
-			m.Z = d.Cfg.MaxDustDepth * math.Sqrt(frnd())
+			m.Z = d.Cfg.MaxDustDepth * math.Sqrt(d.Rand.frnd())
 			motes[n] = m
 		}
 
@@ -13,6 +13,7 @@ type Fog struct {
 
 	Engine   *engine.Engine
 	Resource *engine.Resource
+	Rand     *Random
 
 	ScrollOffset float64
 
@@ -20,10 +21,11 @@ type Fog struct {
 }
 
 // Returns new fog
-func NewFog(e *engine.Engine, r *engine.Resource) *Fog {
+func NewFog(e *engine.Engine, r *engine.Resource, rnd *Random) *Fog {
 	b := &Fog{}
 	b.Engine = e
 	b.Resource = r
+	b.Rand = rnd
 
 	return b
 }
@@ -36,12 +38,14 @@ func (b *Fog) Init() {
 	b.Backgrounds[2] = b.Resource.Background3
 
 	// Random background
-	b.Texture = b.Backgrounds[rnd(0, 3)]
+	b.Texture = b.Backgrounds[b.Rand.rnd(0, 3)]
 	b.Query()
 
 	// Set texture transparency
-	b.Texture.SetBlendMode(sdl.BLENDMODE_BLEND)
-	b.Texture.SetAlphaMod(30)
+	if b.Texture != nil {
+		b.Texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+		b.Texture.SetAlphaMod(30)
+	}
 
 	b.Flags = DRAW
 }
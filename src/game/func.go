@@ -10,30 +10,40 @@ const (
 	smidge = 0.0001
 )
 
+// Random number generator
+type Random struct {
+	*rand.Rand
+}
+
+// Returns new random number generator
+func NewRandom(seed int64) *Random {
+	return &Random{rand.New(rand.NewSource(seed))}
+}
+
 // Generates a random number in a given range
-func rnd(min, max int) int {
-	return rand.Intn(max-min) + min
+func (r *Random) rnd(min, max int) int {
+	return r.Intn(max-min) + min
 }
 
 // Generates a random float number in a given range
-func srnd(min, max float32) float32 {
-	return rand.Float32()*(max-min) + min
+func (r *Random) srnd(min, max float32) float32 {
+	return r.Float32()*(max-min) + min
 }
 
 // Generates a random number in [0,0xffffffff]
-func urnd() uint32 {
-	return uint32(rand.Intn(math.MaxInt32))
+func (r *Random) urnd() uint32 {
+	return uint32(r.Intn(math.MaxInt32))
 }
 
 // Generates a random number in [0, 1]
-func frnd() float64 {
+func (r *Random) frnd() float64 {
 	n := 1.0 + math.MaxInt32
-	return float64(urnd()) / n
+	return float64(r.urnd()) / n
 }
 
 // Generates a random number in [-0.5, 0.5]
-func crnd() float64 {
-	m := int32(urnd()) - math.MinInt32
+func (r *Random) crnd() float64 {
+	m := int32(r.urnd()) - math.MinInt32
 	n := 1.0 + math.MaxInt32
 	return float64(m) / float64(n)
 }
@@ -44,8 +54,8 @@ func toTicks(seconds int, gamespeed float64) float64 {
 }
 
 // Weighted random range
-func weightedRndRange(min, max float64) float64 {
-	return math.Sqrt(min*min + frnd()*(max*max-min*min))
+func (r *Random) weightedRndRange(min, max float64) float64 {
+	return math.Sqrt(min*min + r.frnd()*(max*max-min*min))
 }
 
 // Wraps f so it's within the range [smidge..(max-smidge)]
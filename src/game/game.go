@@ -4,13 +4,13 @@ package game
 import (
 	"fmt"
 	"math"
-	"runtime"
+	"strings"
+	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
-	"github.com/veandco/go-sdl2/sdl_mixer"
 
 	"github.com/gen2brain/vov/src/engine"
-	"github.com/gen2brain/vov/src/system/rumble"
+	"github.com/gen2brain/vov/src/system/log"
 )
 
 // Game states
@@ -38,6 +38,13 @@ type Direction struct {
 	Y2     float32
 	Motion bool
 	State  []bool
+
+	// Analog thrust levels, 0 is full thrust
+	Power [4]uint8
+
+	// Last controller stick position
+	StickX int16
+	StickY int16
 }
 
 // Sets all direction states to state
@@ -45,6 +52,54 @@ func (d *Direction) SetStates(state bool) {
 	for i := 0; i < len(d.State); i++ {
 		d.State[i] = state
 	}
+
+	d.Power = [4]uint8{}
+}
+
+// Returns thrust of direction in range 0..1
+func (d *Direction) Thrust(dir int) float64 {
+	if !d.State[dir] {
+		return 0
+	}
+
+	if d.Power[dir] == 0 {
+		return 1
+	}
+
+	return float64(d.Power[dir]) / 255
+}
+
+// Returns thrust vector, components in range -1..1
+func (d *Direction) Vector() (x, y float64) {
+	x = d.Thrust(RIGHT) - d.Thrust(LEFT)
+	y = d.Thrust(DOWN) - d.Thrust(UP)
+	return
+}
+
+// Sets direction states from thrust vector, magnitude is limited to 1
+func (d *Direction) SetVector(x, y float64) {
+	if m := math.Hypot(x, y); m > 1 {
+		x, y = x/m, y/m
+	}
+
+	d.SetAxis(LEFT, RIGHT, x)
+	d.SetAxis(UP, DOWN, y)
+}
+
+// Sets direction pair from axis value in range -1..1
+func (d *Direction) SetAxis(neg, pos int, value float64) {
+	level := uint8(math.Abs(value)*254 + 1)
+
+	d.State[neg] = value < 0
+	d.State[pos] = value > 0
+	d.Power[neg] = 0
+	d.Power[pos] = 0
+
+	if value < 0 {
+		d.Power[neg] = level
+	} else if value > 0 {
+		d.Power[pos] = level
+	}
 }
 
 // Checks if any of direction states is enabled
@@ -58,6 +113,24 @@ func (d *Direction) StateEnabled() bool {
 	return false
 }
 
+// Returns direction states as bitmask
+func (d *Direction) Mask() (mask uint8) {
+	for i := 0; i < len(d.State); i++ {
+		if d.State[i] {
+			mask |= 1 << uint(i)
+		}
+	}
+
+	return
+}
+
+// Sets direction states from bitmask
+func (d *Direction) SetMask(mask uint8) {
+	for i := 0; i < len(d.State); i++ {
+		d.State[i] = mask&(1<<uint(i)) != 0
+	}
+}
+
 // Game structure
 type Game struct {
 	Engine   *engine.Engine
@@ -78,6 +151,9 @@ type Game struct {
 	PausedText   *Sprite
 	GameOverText *Sprite
 
+	// Gif exported from pause screen
+	GifSaved bool
+
 	Fog    *Fog
 	Dust   *Dust
 	Dots   *Dots
@@ -91,7 +167,50 @@ type Game struct {
 
 	Direction *Direction
 
+	// Seed of random number generator
+	Seed int64
+	// Random number generator
+	Rand *Random
+
+	// Simulation ticks
+	Tick int
+
+	// Scrolled distance, world position is screen position plus scroll
+	ScrollX float64
+	ScrollY float64
+
+	// Ghost of best run, nil if disabled
+	Ghost *Ghost
+
+	// Virtual joystick, nil if disabled
+	Stick *Stick
+
+	// Fingers on screen
+	Touches *Touches
+
+	// Accelerometer steering
+	Tilt *Tilt
+
+	// Date of daily run, empty for normal game
+	Daily string
+
+	// Replay being recorded
+	Replay *Replay
+	// Replay file name, set when recorded replay is saved
+	ReplayFile string
+
+	// Replay being played, inputs are read from it instead of events
+	Playback *Replay
+	// Config values replaced with playback
+	SavedConfig ReplayConfig
+
+	// Engine tuning reloads seen by game
+	TuningReloads int
+
 	Score int
+
+	// Gameplay events
+	Events *EventBus
 }
 
 // Returns new game
@@ -102,30 +221,136 @@ func NewGame(e *engine.Engine, r *engine.Resource) (g *Game) {
 	g.Resource = r
 
 	g.Direction = &Direction{}
+	g.Touches = NewTouches()
+	g.Tilt = NewTilt()
+
+	g.Seed = time.Now().UTC().UnixNano()
+	if e.Cfg.Seed != 0 {
+		g.Seed = e.Cfg.Seed
+	}
+	g.Rand = NewRandom(g.Seed)
 
-	g.Fog = NewFog(e, r)
-	g.Dust = NewDust(e)
+	g.Fog = NewFog(e, r, g.Rand)
+	g.Dust = NewDust(e, g.Rand)
 	g.Dots = NewDots(g)
 	g.Ship = NewShip(g)
 	g.Powups = NewPowups(g)
-	g.Rocks = NewRocks(e, r)
+	g.Rocks = NewRocks(e, r, g.Rand)
+
+	g.Events = NewEventBus()
+	g.SubscribeAudio()
+	g.SubscribeHaptics()
+
+	return
+}
+
+// Returns new game that plays replay
+func NewReplayGame(e *engine.Engine, r *engine.Resource, rep *Replay) (g *Game) {
+	// Replace simulation config, it is restored in OnQuit
+	var saved ReplayConfig
+	saved.Get(e.Cfg)
+	rep.Header.Config.Set(e.Cfg)
+
+	if rep.Header.Config.WinWidth != e.Cfg.WinWidth || rep.Header.Config.WinHeight != e.Cfg.WinHeight {
+		e.SetDimensions(int(rep.Header.Config.WinWidth), int(rep.Header.Config.WinHeight))
+		if e.Renderer != nil {
+			e.Renderer.SetLogicalSize(int(e.Cfg.WinWidth), int(e.Cfg.WinHeight))
+		}
+	}
+
+	// Rocks are generated with resource seed
+	if r.Seed != rep.Header.RocksSeed {
+		r.ReloadRocks(rep.Header.RocksSeed)
+	}
+
+	g = NewGame(e, r)
+	g.SetSeed(rep.Header.Seed)
+
+	g.Playback = rep
+	g.Playback.Rewind()
+	g.SavedConfig = saved
 
 	return
 }
 
+// Returns new daily game, seeded from current UTC date
+func NewDailyGame(e *engine.Engine, r *engine.Resource) (g *Game) {
+	now := time.Now().UTC()
+
+	g = NewGame(e, r)
+	g.Daily = now.Format("2006-01-02")
+	g.SetSeed(DailySeed(now))
+
+	return
+}
+
+// Returns seed for date
+func DailySeed(t time.Time) int64 {
+	y, m, d := t.Date()
+	return int64(y*10000 + int(m)*100 + d)
+}
+
+// Sets seed of random number generator, must be called before OnInit
+func (g *Game) SetSeed(seed int64) {
+	g.Seed = seed
+	g.Rand.Seed(seed)
+}
+
 // Initializes game state
 func (g *Game) OnInit() bool {
+	g.Init()
+
+	// Record replay, variable steps can't be replayed
+	if g.Playback == nil && g.Engine.FixedStep() && !g.Engine.Headless {
+		g.Replay = NewReplay(g)
+	}
+
+	// Ghost of best run
+	if g.Playback == nil && g.Cfg.GhostEnabled {
+		g.InitGhost()
+	}
+
+	// Virtual joystick for touch and mouse
+	g.Stick = nil
+	if g.Playback == nil && g.Cfg.VirtualStick {
+		g.Stick = NewStick(g)
+	}
+
+	// Record frames for gif
+	if g.Playback == nil {
+		g.Engine.StartRecording()
+	}
+
+	// Play game music
+	g.Resource.PlayMusic(g.Resource.MusicGame, -1)
+
+	return true
+}
+
+// Initializes simulation and sprites, without recording or music
+func (g *Game) Init() {
 	g.Direction.State = make([]bool, 4)
 
+	// Daily rocks are same for everyone
+	if g.Daily != "" && g.Resource.Seed != g.Seed {
+		g.Resource.ReloadRocks(g.Seed)
+	}
+
+	// Tuning reloads are checked against value at start
+	g.TuningReloads = g.Engine.TuningReloads
+
+	// Reset values changed by previous game
+	g.Cfg.ResetRuntime()
+
+	g.Engine.ScreenDX = g.Cfg.BarrierSpeed
+	g.Engine.ScreenDY = 0.0
+
 	// Initialize ship
 	g.Ship.Init()
 
 	g.Ship.DX = g.Engine.ScreenDX
 	g.Ship.DY = g.Engine.ScreenDY
 
-	g.Engine.ScreenDX = g.Cfg.BarrierSpeed
-	g.Engine.ScreenDY = 0.0
-
 	// Create sprites
 	g.Life = NewSprite(g.Engine, g.Resource.Life)
 
@@ -172,15 +397,51 @@ func (g *Game) OnInit() bool {
 	g.Rocks.Init()
 	g.Powups.Init()
 
-	// Play game music
-	g.Resource.PlayMusic(g.Resource.MusicGame, -1)
+	// Neutral orientation is captured at start
+	g.Tilt.Calibrate()
+}
 
-	return true
+// Initializes ghost from best score replay
+func (g *Game) InitGhost() {
+	file := BestReplay()
+	if file == "" {
+		return
+	}
+
+	rep, err := LoadReplay(file)
+	if err != nil {
+		log.Error("LoadReplay: %s\n", err)
+		return
+	}
+
+	if len(rep.Track) == 0 {
+		return
+	}
+
+	g.Ghost = NewGhost(g, rep)
+	g.Ghost.Init()
 }
 
 // Quits game state
 func (g *Game) OnQuit() bool {
-	mix.HaltMusic()
+	g.Resource.FadeOutMusic()
+	g.Engine.StopRecording()
+
+	if g.Playback != nil {
+		// Restore config
+		g.SavedConfig.Set(g.Cfg)
+
+		if g.SavedConfig.WinWidth != g.Cfg.WinWidth || g.SavedConfig.WinHeight != g.Cfg.WinHeight {
+			g.Engine.SetDimensions(int(g.SavedConfig.WinWidth), int(g.SavedConfig.WinHeight))
+			if g.Engine.Renderer != nil {
+				g.Engine.Renderer.SetLogicalSize(int(g.Cfg.WinWidth), int(g.Cfg.WinHeight))
+			}
+		}
+	}
+
+	// Powups don't outlive game
+	g.Cfg.ResetRuntime()
+
 	return true
 }
 
@@ -191,7 +452,7 @@ func (g *Game) String() string {
 
 // Handles input events
 func (g *Game) HandleEvents() {
-	//if engine.Paused {
+	//if g.Engine.Clock.Paused {
 	//event := sdl.WaitEvent()
 	//if event != nil {
 	//g.HandleEvent(event)
@@ -205,144 +466,59 @@ func (g *Game) HandleEvents() {
 
 // Handles input event
 func (g *Game) HandleEvent(event sdl.Event) {
+	if g.Playback != nil {
+		g.HandlePlaybackEvent(event)
+		return
+	}
+
+	// Handle analog stick in game
+	if t, ok := event.(*sdl.ControllerAxisEvent); ok && g.State == GamePlay && !g.Engine.Clock.Paused {
+		g.HandleAxis(t)
+		return
+	}
+
+	// Handle mapped actions
+	if a, pressed := g.Engine.Input.Actions(event); a != 0 {
+		g.HandleAction(a, pressed)
+		return
+	}
+
 	switch t := event.(type) {
 	case *sdl.QuitEvent:
 		// Handle quit event
 		g.Engine.Quit()
 
-	case *sdl.KeyDownEvent:
-		// Handle pause
-		if t.Keysym.Scancode == sdl.SCANCODE_ESCAPE || t.Keysym.Scancode == sdl.SCANCODE_AC_BACK ||
-			t.Keysym.Scancode == sdl.SCANCODE_P || t.Keysym.Scancode == sdl.SCANCODE_PAUSE ||
-			t.Keysym.Scancode == sdl.SCANCODE_SPACE {
-
-			if t.Keysym.Scancode == sdl.SCANCODE_ESCAPE || t.Keysym.Scancode == sdl.SCANCODE_AC_BACK {
-				if engine.Paused {
-					engine.Unpause()
-					g.Engine.State.Change(NewMenu(g.Engine, g.Resource))
-					break
-				}
-			}
-
-			g.TogglePause()
-		}
-
-		// Handle fullscreen
-		if (t.Keysym.Mod&sdl.KMOD_ALT != 0 && t.Keysym.Scancode == sdl.SCANCODE_RETURN) || t.Keysym.Scancode == sdl.SCANCODE_F11 {
-			g.Engine.Fullscreen()
-			break
-		}
-
-		if g.State != GamePlay {
-			break
-		}
-
-		// Handle keys directions
-		switch t.Keysym.Scancode {
-		case sdl.SCANCODE_LEFT:
-			g.Ship.PlaySound()
-			g.Direction.State[LEFT] = true
-		case sdl.SCANCODE_RIGHT:
-			g.Ship.PlaySound()
-			g.Direction.State[RIGHT] = true
-		case sdl.SCANCODE_UP:
-			g.Ship.PlaySound()
-			g.Direction.State[UP] = true
-		case sdl.SCANCODE_DOWN:
-			g.Ship.PlaySound()
-			g.Direction.State[DOWN] = true
-		}
-	case *sdl.KeyUpEvent:
-		g.Ship.FadeSound()
-
-		// Handle keys directions
-		switch t.Keysym.Scancode {
-		case sdl.SCANCODE_LEFT:
-			g.Direction.State[LEFT] = false
-		case sdl.SCANCODE_RIGHT:
-			g.Direction.State[RIGHT] = false
-		case sdl.SCANCODE_UP:
-			g.Direction.State[UP] = false
-		case sdl.SCANCODE_DOWN:
-			g.Direction.State[DOWN] = false
-		}
-
 	case *sdl.TouchFingerEvent:
-		if engine.Paused {
-			g.TogglePause()
-			break
-		}
+		g.HandleTouch(t)
 
-		if g.State != GamePlay {
+	case *sdl.MouseMotionEvent:
+		// Touch is handled per finger
+		if t.Which == sdl.TOUCH_MOUSEID {
 			break
 		}
 
-		// Handle touch directions
-		if t.Type == sdl.FINGERDOWN {
-			g.Direction.X1 = t.X
-			g.Direction.Y1 = t.Y
-		} else if t.Type == sdl.FINGERMOTION {
-			g.Direction.X2 = t.X
-			g.Direction.Y2 = t.Y
-
-			g.Direction.Motion = true
-
-			xDiff := g.Direction.X2 - g.Direction.X1
-			yDiff := g.Direction.Y2 - g.Direction.Y1
-
-			if math.Abs(float64(xDiff)) > math.Abs(float64(yDiff)) && math.Abs(float64(xDiff)) > g.Engine.Cfg.TouchThreshold {
-				g.Ship.PlaySound()
-
-				if g.Direction.X1 < g.Direction.X2 {
-					g.Direction.State[RIGHT] = true
-				} else {
-					g.Direction.State[LEFT] = true
-				}
-			} else if math.Abs(float64(yDiff)) > math.Abs(float64(xDiff)) && math.Abs(float64(yDiff)) > g.Engine.Cfg.TouchThreshold {
-				g.Ship.PlaySound()
-
-				if g.Direction.Y1 > g.Direction.Y2 {
-					g.Direction.State[UP] = true
-				} else {
-					g.Direction.State[DOWN] = true
-				}
-			}
-		} else if t.Type == sdl.FINGERUP {
-			g.Ship.FadeSound()
-			g.Direction.Motion = false
-			g.Direction.SetStates(false)
-		}
-
-	case *sdl.MouseMotionEvent:
 		// Handle mouse directions
 		if g.Direction.Motion {
 			g.Direction.X2 = float32(t.X)
 			g.Direction.Y2 = float32(t.Y)
 
-			xDiff := g.Direction.X2 - g.Direction.X1
-			yDiff := g.Direction.Y2 - g.Direction.Y1
+			if g.Stick != nil {
+				g.Stick.Move(float64(t.X), float64(t.Y))
+			}
 
-			if math.Abs(float64(xDiff)) > math.Abs(float64(yDiff)) {
-				g.Ship.PlaySound()
+			xDiff := float64(g.Direction.X2 - g.Direction.X1)
+			yDiff := float64(g.Direction.Y2 - g.Direction.Y1)
 
-				if g.Direction.X1 < g.Direction.X2 {
-					g.Direction.State[RIGHT] = true
-				} else {
-					g.Direction.State[LEFT] = true
-				}
-			} else {
-				g.Ship.PlaySound()
-
-				if g.Direction.Y1 > g.Direction.Y2 {
-					g.Direction.State[UP] = true
-				} else {
-					g.Direction.State[DOWN] = true
-				}
-			}
+			g.Drag(xDiff, yDiff)
 		}
 
 	case *sdl.MouseButtonEvent:
-		if engine.Paused {
+		// Touch is handled per finger
+		if t.Which == sdl.TOUCH_MOUSEID {
+			break
+		}
+
+		if g.Engine.Clock.Paused {
 			g.TogglePause()
 			break
 		}
@@ -357,10 +533,18 @@ func (g *Game) HandleEvent(event sdl.Event) {
 			g.Direction.Y1 = float32(t.Y)
 
 			g.Direction.Motion = true
+
+			if g.Stick != nil {
+				g.Stick.Start(float64(t.X), float64(t.Y))
+			}
 		} else if t.Type == sdl.MOUSEBUTTONUP && t.Button == sdl.BUTTON_LEFT {
 			g.Ship.FadeSound()
 			g.Direction.Motion = false
 			g.Direction.SetStates(false)
+
+			if g.Stick != nil {
+				g.Stick.Stop()
+			}
 		}
 
 	case *sdl.ControllerDeviceEvent:
@@ -374,108 +558,226 @@ func (g *Game) HandleEvent(event sdl.Event) {
 			g.Engine.CloseController()
 		}
 
-	case *sdl.ControllerButtonEvent:
-		// Handle pause
-		if t.Type == sdl.CONTROLLERBUTTONDOWN {
-			if t.Button == sdl.CONTROLLER_BUTTON_A || t.Button == sdl.CONTROLLER_BUTTON_START || t.Button == sdl.CONTROLLER_BUTTON_B {
-				if engine.Paused {
-					g.TogglePause()
-					break
-				}
+	case *sdl.JoyAxisEvent:
+		if !g.Engine.Cfg.AccelerometerEnabled || !g.Engine.Accelerometer || t.Which != g.Engine.AccelerometerID {
+			break
+		}
 
-				g.TogglePause()
-			}
+		// Accelerometer is applied in UpdateTilt
+		g.Tilt.Axis(int(t.Axis), t.Value)
 
-			if t.Button == sdl.CONTROLLER_BUTTON_BACK {
-				if engine.Paused {
-					engine.Unpause()
-				}
+	default:
+		break
+	}
+}
 
-				g.Engine.State.Change(NewMenu(g.Engine, g.Resource))
-			}
+// Handles touch event, first finger steers and tap of another finger pauses
+func (g *Game) HandleTouch(t *sdl.TouchFingerEvent) {
+	switch t.Type {
+	case sdl.FINGERDOWN:
+		if g.Engine.Clock.Paused {
+			g.TogglePause()
+			break
 		}
 
-	case *sdl.ControllerAxisEvent:
 		if g.State != GamePlay {
 			break
 		}
 
-		// Handle joystick directions
-		switch t.Axis {
-		case sdl.CONTROLLER_AXIS_LEFTX:
-			if t.Value < 0 {
-				g.Ship.PlaySound()
-				g.Direction.State[LEFT] = true
-			} else if t.Value > 0 {
-				g.Ship.PlaySound()
-				g.Direction.State[RIGHT] = true
-			} else if t.Value == 0 {
-				g.Ship.FadeSound()
-				g.Direction.State[LEFT] = false
-				g.Direction.State[RIGHT] = false
-			}
+		touch := g.Touches.Down(t)
+		if g.Touches.Steering != nil {
+			break
+		}
 
-		case sdl.CONTROLLER_AXIS_LEFTY:
-			if t.Value < 0 {
-				g.Ship.PlaySound()
-				g.Direction.State[UP] = true
-			} else if t.Value > 0 {
-				g.Ship.PlaySound()
-				g.Direction.State[DOWN] = true
-			} else if t.Value == 0 {
-				g.Ship.FadeSound()
-				g.Direction.State[UP] = false
-				g.Direction.State[DOWN] = false
-			}
+		g.Touches.Steering = touch
+
+		g.Direction.X1 = t.X
+		g.Direction.Y1 = t.Y
+
+		if g.Stick != nil {
+			g.Stick.Start(float64(t.X)*g.Engine.Cfg.WinWidth, float64(t.Y)*g.Engine.Cfg.WinHeight)
 		}
 
-	case *sdl.JoyAxisEvent:
-		if runtime.GOOS != "android" || !g.Engine.Cfg.AccelerometerEnabled {
+	case sdl.FINGERMOTION:
+		touch := g.Touches.Motion(t, g.Engine.Cfg.TouchThreshold)
+		if touch == nil || touch != g.Touches.Steering || g.State != GamePlay {
 			break
 		}
 
-		if g.State != GamePlay || g.Direction.Motion {
+		// Steering resumes after pause from current position
+		if touch.Paused {
+			touch.Paused = false
+
+			g.Direction.X1 = t.X
+			g.Direction.Y1 = t.Y
+
+			if g.Stick != nil {
+				g.Stick.Start(float64(t.X)*g.Engine.Cfg.WinWidth, float64(t.Y)*g.Engine.Cfg.WinHeight)
+			}
+		}
+
+		g.Direction.X2 = t.X
+		g.Direction.Y2 = t.Y
+
+		g.Direction.Motion = true
+
+		if g.Stick != nil {
+			g.Stick.Move(float64(t.X)*g.Engine.Cfg.WinWidth, float64(t.Y)*g.Engine.Cfg.WinHeight)
+		}
+
+		// Touch coordinates are normalized
+		xDiff := float64(g.Direction.X2-g.Direction.X1) * g.Engine.Cfg.WinWidth
+		yDiff := float64(g.Direction.Y2-g.Direction.Y1) * g.Engine.Cfg.WinHeight
+
+		g.Drag(xDiff, yDiff)
+
+	case sdl.FINGERUP:
+		steering := g.Touches.Steering
+		touch := g.Touches.Up(t)
+		if touch == nil {
 			break
 		}
 
-		// TODO
-		// Handle accelerometer directions
-		switch t.Axis {
-		case 0:
-			xDiff := g.Direction.X1 - float32(t.Value)
-			g.Direction.X1 = float32(t.Value)
+		if touch == steering {
+			g.Ship.FadeSound()
+			g.Direction.Motion = false
+			g.Direction.SetStates(false)
 
-			if t.Value < 0 && math.Abs(float64(xDiff)) > g.Engine.Cfg.AccelThreshold {
-				g.Ship.PlaySound()
-				g.Direction.State[LEFT] = true
-			} else if t.Value > 0 && math.Abs(float64(xDiff)) > g.Engine.Cfg.AccelThreshold {
-				g.Ship.PlaySound()
-				g.Direction.State[RIGHT] = true
-			} else {
-				g.Ship.FadeSound()
-				g.Direction.State[LEFT] = false
-				g.Direction.State[RIGHT] = false
+			if g.Stick != nil {
+				g.Stick.Stop()
+			}
+		} else if !touch.Moved && t.Timestamp-touch.Timestamp < uint32(g.Cfg.TapLength) {
+			// Tap of other finger
+			g.HandleAction(engine.ACTION_PAUSE, true)
+		}
+	}
+}
+
+// Handles input action
+func (g *Game) HandleAction(a engine.Action, pressed bool) {
+	if pressed {
+		switch {
+		case a&engine.ACTION_BACK != 0:
+			if g.Engine.Clock.Paused {
+				// Ask before leaving paused game
+				g.Engine.State.Push(NewConfirm(g.Engine, g.Resource, "QUIT TO MENU?", func() {
+					g.Engine.Unpause()
+					g.Engine.State.Change(NewMenu(g.Engine, g.Resource))
+				}))
+				return
 			}
 
-		case 1:
-			yDiff := g.Direction.Y1 - float32(t.Value)
-			g.Direction.Y1 = float32(t.Value)
+			g.TogglePause()
+			return
 
-			if t.Value < 0 && math.Abs(float64(yDiff)) > g.Engine.Cfg.AccelThreshold {
-				g.Ship.PlaySound()
-				g.Direction.State[UP] = true
-			} else if t.Value > 0 && math.Abs(float64(yDiff)) > g.Engine.Cfg.AccelThreshold {
+		case a&engine.ACTION_PAUSE != 0:
+			g.TogglePause()
+			return
+
+		case a&engine.ACTION_CONFIRM != 0 && g.Engine.Clock.Paused:
+			g.TogglePause()
+			return
+
+		case a&engine.ACTION_EXPORT_GIF != 0 && g.Engine.Clock.Paused:
+			// Export gif from pause screen
+			g.ExportGif()
+			return
+
+		case a&engine.ACTION_TOGGLE_FULLSCREEN != 0:
+			g.Engine.Fullscreen()
+			return
+		}
+
+		if g.State != GamePlay {
+			return
+		}
+	} else {
+		g.Ship.FadeSound()
+	}
+
+	// Handle directions
+	dirs := []struct {
+		action engine.Action
+		dir    int
+	}{
+		{engine.ACTION_THRUST_LEFT, LEFT},
+		{engine.ACTION_THRUST_RIGHT, RIGHT},
+		{engine.ACTION_THRUST_UP, UP},
+		{engine.ACTION_THRUST_DOWN, DOWN},
+	}
+
+	for _, d := range dirs {
+		if a&d.action != 0 {
+			if pressed {
 				g.Ship.PlaySound()
-				g.Direction.State[DOWN] = true
-			} else {
-				g.Ship.FadeSound()
-				g.Direction.State[UP] = false
-				g.Direction.State[DOWN] = false
 			}
+			g.Direction.State[d.dir] = pressed
+			g.Direction.Power[d.dir] = 0
 		}
+	}
+}
 
+// Handles controller stick, thrust follows stick angle and deflection
+func (g *Game) HandleAxis(t *sdl.ControllerAxisEvent) {
+	switch t.Axis {
+	case sdl.CONTROLLER_AXIS_LEFTX:
+		g.Direction.StickX = t.Value
+	case sdl.CONTROLLER_AXIS_LEFTY:
+		g.Direction.StickY = t.Value
 	default:
-		break
+		return
+	}
+
+	g.Direction.SetVector(g.Engine.Input.Stick(g.Direction.StickX, g.Direction.StickY))
+	g.UpdateSound()
+}
+
+// Sets thrust from drag distance in pixels, full thrust at DragRadius
+func (g *Game) Drag(xDiff, yDiff float64) {
+	threshold := g.Cfg.TouchThreshold * g.Cfg.WinHeight
+	radius := g.Cfg.DragRadius * g.Cfg.WinHeight
+
+	dist := math.Hypot(xDiff, yDiff)
+	if dist <= threshold || radius <= threshold {
+		g.Direction.SetVector(0, 0)
+	} else {
+		m := math.Min((dist-threshold)/(radius-threshold), 1)
+		g.Direction.SetVector(xDiff/dist*m, yDiff/dist*m)
+	}
+
+	g.UpdateSound()
+}
+
+// Plays or fades ship sound by direction states
+func (g *Game) UpdateSound() {
+	if g.Direction.StateEnabled() {
+		g.Ship.PlaySound()
+	} else {
+		g.Ship.FadeSound()
+	}
+}
+
+// Handles input event while replay is played
+func (g *Game) HandlePlaybackEvent(event sdl.Event) {
+	if _, ok := event.(*sdl.QuitEvent); ok {
+		// Handle quit event
+		g.Engine.Quit()
+		return
+	}
+
+	a, pressed := g.Engine.Input.Actions(event)
+	if !pressed {
+		return
+	}
+
+	if a&engine.ACTION_BACK != 0 {
+		if g.Engine.Clock.Paused {
+			g.Engine.Unpause()
+		}
+
+		g.Engine.State.Change(NewMenu(g.Engine, g.Resource))
+	} else if a&engine.ACTION_PAUSE != 0 {
+		g.TogglePause()
 	}
 }
 
@@ -485,24 +787,137 @@ func (g *Game) TogglePause() {
 		return
 	}
 
-	if !engine.Paused {
+	// Record pause toggle
+	if g.Replay != nil {
+		g.Replay.Record(g.Tick, EventPause, 0)
+	}
+
+	if !g.Engine.Clock.Paused {
 		g.Ship.FadeSound()
 		g.Resource.PlaySound(g.Resource.SoundClick, -1, 0)
 
 		g.Direction.SetStates(false)
-		engine.Pause()
+		g.Engine.Pause()
+
+		if g.Stick != nil {
+			g.Stick.Stop()
+		}
+
+		// Fingers that stay down keep steering after pause
+		g.Touches.Pause()
+
+		g.GifSaved = false
 
 		g.LastState = g.State
-		g.State = GamePause
+		g.SetState(GamePause)
 	} else {
 		g.Resource.PlaySound(g.Resource.SoundClick, -1, 0)
-		engine.Unpause()
-		g.State = g.LastState
+		g.Engine.Unpause()
+		g.SetState(g.LastState)
+
+		// Device may be held differently after pause
+		g.Tilt.Calibrate()
+	}
+}
+
+// Exports last seconds of play as gif
+func (g *Game) ExportGif() {
+	if g.GifSaved {
+		return
+	}
+
+	err := g.Engine.ExportGif()
+	if err != nil {
+		log.Error("ExportGif: %s\n", err)
+		return
+	}
+
+	g.GifSaved = true
+	g.Resource.PlaySound(g.Resource.SoundClick, -1, 0)
+}
+
+// Changes game state and publishes it
+func (g *Game) SetState(state int) {
+	last := g.State
+	g.State = state
+
+	g.Events.Publish(Event{Type: StateChanged, State: state, LastState: last})
+}
+
+// Updates direction states from accelerometer
+func (g *Game) UpdateTilt() {
+	if g.Playback != nil || !g.Cfg.AccelerometerEnabled || g.State != GamePlay || g.Direction.Motion {
+		return
+	}
+
+	g.Tilt.Update(g.Cfg.AccelSmoothing)
+	if !g.Tilt.Active {
+		return
+	}
+
+	moving := g.Direction.StateEnabled()
+	g.Direction.SetVector(g.Tilt.Vector(g.Cfg.AccelSensitivity, g.Cfg.AccelThreshold))
+
+	if moving != g.Direction.StateEnabled() {
+		g.UpdateSound()
+	}
+}
+
+// Updates direction states from replay or records them
+func (g *Game) UpdateReplay() {
+	if g.Playback != nil {
+		g.Direction.SetMask(g.Playback.MaskAt(g.Tick))
+		g.Direction.Power = g.Playback.Power
+	} else if g.Replay != nil {
+		g.Replay.RecordMask(g.Tick, g.Direction.Mask(), g.Direction.Power)
+	}
+}
+
+// Saves recorded replay
+func (g *Game) SaveReplay() {
+	if g.Replay == nil {
+		return
+	}
+
+	g.Replay.Header.Score = g.Score
+	g.Replay.Header.Ticks = g.Tick
+
+	file, err := g.Replay.Save()
+	g.Replay = nil
+	if err != nil {
+		log.Error("Save: %s\n", err)
+		return
+	}
+
+	g.ReplayFile = file
+}
+
+// Handles tuning reload during game, recorded replay can't be played back and is not saved.
+// Playback keeps values from replay, reloaded values are restored after it
+func (g *Game) TuningReloaded() {
+	if g.Playback != nil {
+		g.SavedConfig.GetLive(g.Cfg)
+
+		slowed := g.Cfg.Speed != g.Cfg.GameSpeed
+		g.Playback.Header.Config.Set(g.Cfg)
+		if !slowed {
+			g.Cfg.Speed = g.Cfg.GameSpeed
+		}
+		return
+	}
+
+	if g.Replay != nil {
+		g.Replay = nil
+		g.Engine.ShowMessage("tuning reloaded, replay of this run is not saved")
 	}
 }
 
 // Updates game state
 func (g *Game) UpdateState() {
+	// Accelerometer and replay inputs
+	g.UpdateTilt()
+	g.UpdateReplay()
+
 	if g.StateTimeout > 0 {
 		g.StateTimeout -= g.Engine.TFrame * 3
 		return
@@ -511,22 +926,10 @@ func (g *Game) UpdateState() {
 	switch g.State {
 	case GamePlay:
 		// Update ship direction
-		if g.Direction.State[LEFT] {
-			g.Ship.DX -= g.Cfg.ThrusterStrength * g.Engine.TFrame
-			g.Ship.Jets |= 1 << 0
-		}
-		if g.Direction.State[DOWN] {
-			g.Ship.DY += g.Cfg.ThrusterStrength * g.Engine.TFrame
-			g.Ship.Jets |= 1 << 1
-		}
-		if g.Direction.State[RIGHT] {
-			g.Ship.DX += g.Cfg.ThrusterStrength * g.Engine.TFrame
-			g.Ship.Jets |= 1 << 2
-		}
-		if g.Direction.State[UP] {
-			g.Ship.DY -= g.Cfg.ThrusterStrength * g.Engine.TFrame
-			g.Ship.Jets |= 1 << 3
-		}
+		g.Ship.SetThrust(g.Direction.Vector())
+
+		g.Ship.DX += g.Cfg.ThrusterStrength * g.Ship.ThrustX * g.Engine.TFrame
+		g.Ship.DY += g.Cfg.ThrusterStrength * g.Ship.ThrustY * g.Engine.TFrame
 
 		if g.Ship.Jets != 0 {
 			g.Ship.DX = fconstrain2(g.Ship.DX, -50, 50)
@@ -534,23 +937,18 @@ func (g *Game) UpdateState() {
 		}
 
 	case GameOver:
-		// Stop rumble
-		if g.Engine.Cfg.HapticEnabled {
-			rumble.RumbleStop()
+		// Export gif of last seconds
+		if g.Cfg.GifOnDeath && g.Engine.Clip != nil {
+			g.ExportGif()
 		}
 
 		// Change state
-		g.State = GameQuit
+		g.SetState(GameQuit)
 
 	case DeadPause:
-		// Stop rumble
-		if g.Engine.Cfg.HapticEnabled {
-			rumble.RumbleStop()
-		}
-
 		// Restore the ship
 		g.Ship.Flags = DRAW | MOVE | COLLIDE
-		g.State = GamePlay
+		g.SetState(GamePlay)
 
 		// Change ship state
 		g.Ship.State = INVINCIBLE
@@ -560,16 +958,36 @@ func (g *Game) UpdateState() {
 
 // Updates game
 func (g *Game) Update() {
-	// Don't update if timer is paused
-	if engine.Paused {
+	// Don't update if timer is paused or game is over
+	if g.Engine.Clock.Paused || g.State == GameQuit {
 		return
 	}
 
+	// Tuning reloaded since last update
+	if g.TuningReloads != g.Engine.TuningReloads {
+		g.TuningReloads = g.Engine.TuningReloads
+		g.TuningReloaded()
+	}
+
 	// Update state
 	g.UpdateState()
 
 	// Update objects
 	g.Ship.Update()
+
+	// Update scrolled distance
+	g.ScrollX += g.Engine.ScreenDX * g.Engine.TFrame
+	g.ScrollY += g.Engine.ScreenDY * g.Engine.TFrame
+
+	// Record ship trajectory
+	if g.Replay != nil {
+		g.Replay.RecordTrack(g.Tick, g.Ship.X+g.ScrollX, g.Ship.Y+g.ScrollY, g.Ship.Flags&DRAW != 0)
+	}
+
+	// Update ghost
+	if g.Ghost != nil {
+		g.Ghost.Update()
+	}
 	g.Rocks.Update()
 	g.Powups.Update()
 	g.Dots.Update()
@@ -590,7 +1008,7 @@ func (g *Game) Update() {
 
 	if g.State != GameOver {
 		// Update score
-		g.Score += int(g.Engine.FrameDelta)
+		g.Score += int(g.Engine.StepDelta)
 
 		// Ship engine dots
 		g.Dots.NewShipDots()
@@ -598,6 +1016,17 @@ func (g *Game) Update() {
 		// Ship collisions
 		g.Ship.Collisions()
 	}
+
+	// Reset jets
+	g.Ship.SetThrust(0, 0)
+
+	// Next tick
+	g.Tick++
+
+	// Save replay when game is over
+	if g.State == GameQuit {
+		g.SaveReplay()
+	}
 }
 
 // Draws fps
@@ -674,8 +1103,18 @@ func (g *Game) Draw() {
 	g.Dots.Draw()
 	g.Rocks.Draw()
 	g.Powups.Draw()
+
+	if g.Ghost != nil {
+		g.Ghost.Draw()
+	}
+
 	g.Ship.Draw()
 
+	// Draw virtual joystick
+	if g.Stick != nil && g.State == GamePlay {
+		g.Stick.Draw()
+	}
+
 	// Draw score
 	g.DrawScore()
 
@@ -695,6 +1134,18 @@ func (g *Game) Draw() {
 	// Draw paused text
 	if g.State == GamePause {
 		g.PausedText.Draw()
+
+		// Draw gif hint with currently bound key
+		key := g.Engine.Input.KeyNames[engine.ACTION_EXPORT_GIF]
+		if g.Engine.Clip != nil && (key != "" || g.GifSaved) {
+			text := fmt.Sprintf("PRESS %s TO SAVE GIF", strings.ToUpper(key))
+			if g.GifSaved {
+				text = "GIF SAVED"
+			}
+
+			w, _, _ := g.Resource.FontSmall.SizeUTF8(text)
+			g.Resource.DrawText(text, int32(g.Cfg.WinWidth-float64(w))/2, int32(g.PausedText.Y+g.PausedText.Height)+20, engine.FONT_SMALL)
+		}
 	}
 
 	// Draw gameover text
@@ -702,8 +1153,12 @@ func (g *Game) Draw() {
 		g.GameOverText.Draw()
 	}
 
-	if g.State == GameQuit {
-		// Change state to menu
-		g.Engine.State.Change(NewScores(g.Engine, g.Resource, g.Score, true))
+	if g.State == GameQuit && g.Playback == nil {
+		// Change state to scores, replays are ended by viewer
+		if g.Daily != "" {
+			g.Engine.State.Change(NewDailyScores(g.Engine, g.Resource, g.Score, g.ReplayFile, g.Daily))
+		} else {
+			g.Engine.State.Change(NewScores(g.Engine, g.Resource, g.Score, g.ReplayFile, true))
+		}
 	}
 }
@@ -0,0 +1,129 @@
+// VoV game
+package game
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gen2brain/vov/src/engine"
+)
+
+// Assets used for collision surfaces
+var testDataDir = filepath.Join("..", "..", "android", "assets")
+
+// Returns headless engine and resources with default config, home is temporary directory
+func newTestEngine(t *testing.T, seed int64) (*engine.Engine, *engine.Resource) {
+	if _, err := os.Stat(testDataDir); err != nil {
+		t.Skip("assets not found")
+	}
+
+	dir, err := ioutil.TempDir("", "vov")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+
+	t.Cleanup(func() {
+		os.Setenv("HOME", home)
+		os.RemoveAll(dir)
+	})
+
+	c := &engine.Config{}
+	c.Default()
+
+	e := engine.NewEngine(c)
+	if err := e.InitHeadless(1024, 640); err != nil {
+		t.Fatal(err)
+	}
+
+	r := engine.NewResource(e, testDataDir)
+	r.SetSeed(seed)
+	r.Load()
+
+	t.Cleanup(func() {
+		r.Free()
+		e.Destroy()
+	})
+
+	return e, r
+}
+
+// Runs headless game until it quits, returns score, ticks and ship position
+func runTestGame(t *testing.T, seed int64) (score, ticks int, x, y float64) {
+	e, r := newTestEngine(t, 1)
+
+	g := NewGame(e, r)
+	g.SetSeed(seed)
+	g.OnInit()
+
+	for g.State != GameQuit && g.Tick < 5000 {
+		e.Advance(uint32(e.Cfg.StepLength))
+		g.Update()
+	}
+
+	g.OnQuit()
+
+	if g.State != GameQuit {
+		t.Fatalf("seed %d: game not over after %d ticks", seed, g.Tick)
+	}
+
+	return g.Score, g.Tick, g.Ship.X, g.Ship.Y
+}
+
+func TestHeadlessDeterministic(t *testing.T) {
+	score, ticks, x, y := runTestGame(t, 5)
+
+	score2, ticks2, x2, y2 := runTestGame(t, 5)
+	if score != score2 || ticks != ticks2 || x != x2 || y != y2 {
+		t.Errorf("runs differ: score %d %d, ticks %d %d, ship %g,%g %g,%g", score, score2, ticks, ticks2, x, y, x2, y2)
+	}
+
+	score3, ticks3, _, _ := runTestGame(t, 6)
+	if score == score3 && ticks == ticks3 {
+		t.Errorf("seeds 5 and 6 give same score %d and ticks %d", score, ticks)
+	}
+}
+
+func TestTuningReloaded(t *testing.T) {
+	e, r := newTestEngine(t, 1)
+
+	// Recorded replay is dropped
+	g := NewGame(e, r)
+	g.OnInit()
+	g.Replay = NewReplay(g)
+
+	e.Cfg.BarrierSpeed = 3
+	e.TuningReloads++
+	g.Update()
+
+	if g.Replay != nil {
+		t.Error("replay kept after tuning reload")
+	}
+	g.OnQuit()
+
+	// Playback keeps replay values, reloaded values are restored after it
+	rep := NewReplay(g)
+	rep.Header.Config.BarrierSpeed = 2
+	rep.Header.Ticks = 100
+
+	g = NewReplayGame(e, r, rep)
+	g.OnInit()
+
+	e.Cfg.BarrierSpeed = 4
+	e.TuningReloads++
+	g.Update()
+
+	if e.Cfg.BarrierSpeed != 2 {
+		t.Errorf("playback barrier speed %g, want 2 from replay", e.Cfg.BarrierSpeed)
+	}
+
+	g.OnQuit()
+
+	if e.Cfg.BarrierSpeed != 4 {
+		t.Errorf("barrier speed %g after playback, want reloaded 4", e.Cfg.BarrierSpeed)
+	}
+}
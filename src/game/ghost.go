@@ -0,0 +1,64 @@
+// VoV game
+package game
+
+// Ghost structure, follows ship trajectory of recorded run
+type Ghost struct {
+	Sprite
+
+	Game *Game
+
+	// Replay with trajectory
+	Replay *Replay
+
+	Alpha uint8
+}
+
+// Returns new ghost
+func NewGhost(g *Game, rep *Replay) *Ghost {
+	s := &Ghost{}
+	s.Game = g
+	s.Engine = g.Engine
+	s.Replay = rep
+
+	return s
+}
+
+// Initializes ghost
+func (s *Ghost) Init() {
+	s.Type = SHIP
+	s.Flags = 0
+	s.State = PLAIN
+	s.Alpha = 60
+
+	s.Texture = s.Game.Resource.Ship
+	s.Surface = s.Game.Resource.ShipSurf
+	s.Query()
+
+	s.Width /= float64(6)
+}
+
+// Updates ghost position
+func (s *Ghost) Update() {
+	x, y, visible, ok := s.Replay.TrackAt(s.Game.Tick)
+	if !ok || !visible {
+		s.Flags = 0
+		return
+	}
+
+	s.Flags = DRAW
+
+	// Trajectory is in world coordinates
+	s.X = x - s.Game.ScrollX
+	s.Y = y - s.Game.ScrollY
+}
+
+// Draws ghost
+func (s *Ghost) Draw() {
+	if s.Flags&DRAW == 0 || s.Texture == nil {
+		return
+	}
+
+	// Ship texture is shared, ship sets its own alpha when drawn
+	s.Texture.SetAlphaMod(s.Alpha)
+	s.Sprite.Draw()
+}
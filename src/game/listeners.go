@@ -0,0 +1,78 @@
+// VoV game
+package game
+
+import (
+	"github.com/gen2brain/vov/src/system/rumble"
+)
+
+// Subscribes sound effects to gameplay events
+func (g *Game) SubscribeAudio() {
+	r := g.Resource
+
+	g.Events.Subscribe(RockHit, func(ev Event) {
+		switch ev.State {
+		case PLAIN, SLOWDOWN, ATTACK, ENGINEBLAST:
+			if r.Playing(2) {
+				r.FadeOutChannel(2, 10)
+			}
+			r.PlaySound(r.SoundExplosion2, 2, 0)
+		case SHIELDS:
+			if r.Playing(2) {
+				r.FadeOutChannel(2, 10)
+			}
+			r.PlaySound(r.SoundBounce, 2, 0)
+		case INVINCIBLE:
+			r.PlaySoundTimed(r.SoundEngine3, 2, 0, 200)
+		}
+	})
+
+	g.Events.Subscribe(ShipKilled, func(ev Event) {
+		r.PlaySound(r.SoundExplosion1, 2, 0)
+	})
+
+	g.Events.Subscribe(RockDestroyed, func(ev Event) {
+		// Ship collisions have their own sound
+		if !ev.ByShip {
+			r.PlaySound(r.SoundExplosion2, -1, 0)
+		}
+	})
+
+	g.Events.Subscribe(PowupCollected, func(ev Event) {
+		switch ev.State {
+		case PLAIN:
+			r.PlaySound(r.SoundPowup0, -1, 0)
+		case INVINCIBLE:
+			r.PlaySound(r.SoundPowup1, -1, 0)
+		case ENGINEBLAST:
+			r.PlaySound(r.SoundPowup2, -1, 0)
+		case SHIELDS:
+			r.PlaySound(r.SoundPowup3, -1, 0)
+		case ATTACK:
+			r.PlaySound(r.SoundPowup4, -1, 0)
+		case SLOWDOWN:
+			r.PlaySound(r.SoundPowup5, -1, 0)
+		}
+	})
+}
+
+// Subscribes haptic feedback to gameplay events
+func (g *Game) SubscribeHaptics() {
+	g.Events.Subscribe(ShipKilled, func(ev Event) {
+		if g.Cfg.HapticEnabled {
+			rumble.RumblePlay(1.0, 1000)
+		}
+	})
+
+	g.Events.Subscribe(ShipBounced, func(ev Event) {
+		if g.Cfg.HapticEnabled {
+			rumble.RumblePlay(0.3, 100)
+		}
+	})
+
+	g.Events.Subscribe(StateChanged, func(ev Event) {
+		// Stop rumble when ship is restored or game is over
+		if g.Cfg.HapticEnabled && (ev.LastState == DeadPause || ev.LastState == GameOver) {
+			rumble.RumbleStop()
+		}
+	})
+}
@@ -3,9 +3,9 @@ package game
 
 import (
 	"math"
+	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
-	"github.com/veandco/go-sdl2/sdl_mixer"
 
 	"github.com/gen2brain/vov/src/engine"
 )
@@ -66,8 +66,10 @@ func NewMenu(e *engine.Engine, r *engine.Resource) (m *Menu) {
 	m.Engine = e
 	m.Resource = r
 
-	m.Fog = NewFog(e, r)
-	m.Dust = NewDust(e)
+	rnd := NewRandom(time.Now().UTC().UnixNano())
+
+	m.Fog = NewFog(e, r, rnd)
+	m.Dust = NewDust(e, rnd)
 
 	return
 }
@@ -80,7 +82,8 @@ func (m *Menu) OnInit() bool {
 	// Create buttons
 	m.Buttons = make([]*Button, 0)
 	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.StartText, m.Resource.StartTextHi, NewGame(m.Engine, m.Resource), false))
-	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.ScoresText, m.Resource.ScoresTextHi, NewScores(m.Engine, m.Resource, 0, false), false))
+	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.DailyText, m.Resource.DailyTextHi, NewDailyGame(m.Engine, m.Resource), false))
+	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.ScoresText, m.Resource.ScoresTextHi, NewScores(m.Engine, m.Resource, 0, "", false), false))
 	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.OptionsText, m.Resource.OptionsTextHi, NewOptions(m.Engine, m.Resource), false))
 	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.CreditsText, m.Resource.CreditsTextHi, NewCredits(m.Engine, m.Resource), false))
 
@@ -90,7 +93,7 @@ func (m *Menu) OnInit() bool {
 	m.TitleText = NewSprite(m.Engine, m.Resource.TitleText)
 
 	// Play menu music
-	if !mix.PlayingMusic() {
+	if !m.Resource.PlayingMusic(m.Resource.MusicMenu) {
 		m.Resource.PlayMusic(m.Resource.MusicMenu, -1)
 	}
 
@@ -109,7 +112,7 @@ func (m *Menu) String() string {
 
 // Handles input events
 func (m *Menu) HandleEvents() {
-	if engine.Paused {
+	if m.Engine.Clock.Paused {
 		event := sdl.WaitEvent()
 		if event != nil {
 			m.HandleEvent(event)
@@ -123,59 +126,19 @@ func (m *Menu) HandleEvents() {
 
 // Handles input event
 func (m *Menu) HandleEvent(event sdl.Event) {
+	// Handle mapped actions
+	if a, pressed := m.Engine.Input.Actions(event); a != 0 {
+		if pressed {
+			m.HandleAction(a)
+		}
+		return
+	}
+
 	switch t := event.(type) {
 	case *sdl.QuitEvent:
 		// Handle quit event
 		m.Engine.Quit()
 
-	case *sdl.KeyDownEvent:
-
-		if t.Keysym.Scancode == sdl.SCANCODE_ESCAPE || t.Keysym.Scancode == sdl.SCANCODE_AC_BACK {
-			// Handle quit event
-			m.Engine.Quit()
-		} else if (t.Keysym.Mod&sdl.KMOD_ALT != 0 && t.Keysym.Scancode == sdl.SCANCODE_RETURN) || t.Keysym.Scancode == sdl.SCANCODE_F11 {
-			// Fullscreen
-			m.Engine.Fullscreen()
-		} else if t.Keysym.Scancode == sdl.SCANCODE_RETURN {
-			// Change state on enter
-			m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-			if m.ButtonActive == -1 {
-				m.Engine.State.Change(NewGame(m.Engine, m.Resource))
-			} else {
-				m.Engine.State.Change(m.Buttons[m.ButtonActive].State)
-			}
-		} else if t.Keysym.Scancode == sdl.SCANCODE_UP || t.Keysym.Scancode == sdl.SCANCODE_DOWN {
-			// Change active button on UP/DOWN
-			if t.Keysym.Scancode == sdl.SCANCODE_UP {
-				if m.ButtonActive == -1 {
-					m.ButtonActive = len(m.Buttons) - 1
-				} else {
-					m.ButtonActive--
-					if m.ButtonActive == -1 {
-						m.ButtonActive = len(m.Buttons) - 1
-					}
-				}
-			} else if t.Keysym.Scancode == sdl.SCANCODE_DOWN {
-				if m.ButtonActive == -1 {
-					m.ButtonActive = 0
-				} else {
-					m.ButtonActive++
-					if m.ButtonActive == len(m.Buttons) {
-						m.ButtonActive = 0
-					}
-				}
-			}
-
-			// Set active button
-			for i := 0; i < len(m.Buttons); i++ {
-				if i == m.ButtonActive {
-					m.Buttons[i].Active = true
-				} else {
-					m.Buttons[i].Active = false
-				}
-			}
-		}
-
 	case *sdl.MouseMotionEvent:
 		// Highlight button on hover
 		point := sdl.Point{t.X, t.Y}
@@ -234,72 +197,68 @@ func (m *Menu) HandleEvent(event sdl.Event) {
 			m.Engine.CloseController()
 		}
 
-	case *sdl.ControllerButtonEvent:
-		// Controller buttons
-		if t.Type == sdl.CONTROLLERBUTTONDOWN {
-			if t.Button == sdl.CONTROLLER_BUTTON_BACK {
-				m.Engine.Quit()
-			} else if t.Button == sdl.CONTROLLER_BUTTON_A {
-				if m.ButtonActive != -1 {
-					m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-					m.Engine.State.Change(m.Buttons[m.ButtonActive].State)
-				}
-			} else if t.Button == sdl.CONTROLLER_BUTTON_B {
-				for i := 0; i < len(m.Buttons); i++ {
-					m.Buttons[i].Active = false
-				}
-			} else if t.Button == sdl.CONTROLLER_BUTTON_START {
-				m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-				m.Engine.State.Change(NewGame(m.Engine, m.Resource))
-			}
+	default:
+		break
+	}
+}
+
+// Handles input action
+func (m *Menu) HandleAction(a engine.Action) {
+	switch {
+	case a&engine.ACTION_TOGGLE_FULLSCREEN != 0:
+		m.Engine.Fullscreen()
+
+	case a&engine.ACTION_BACK != 0:
+		// Deselect button, quit if nothing is selected
+		if m.ButtonActive == -1 {
+			m.Engine.Quit()
+			break
 		}
 
-	case *sdl.ControllerAxisEvent:
-		// Controller axises
-		switch t.Axis {
-		case sdl.CONTROLLER_AXIS_LEFTY:
-			// Change active button on UP/DOWN
-			if t.Value < 0 {
-				if m.ButtonActive == -1 {
-					m.ButtonActive = len(m.Buttons) - 1
-				} else {
-					m.ButtonActive--
-					if m.ButtonActive == -1 {
-						m.ButtonActive = len(m.Buttons) - 1
-					}
-				}
-			} else if t.Value > 0 {
-				if m.ButtonActive == -1 {
-					m.ButtonActive = 0
-				} else {
-					m.ButtonActive++
-					if m.ButtonActive == len(m.Buttons) {
-						m.ButtonActive = 0
-					}
-				}
-			}
+		m.ButtonActive = -1
+		for i := 0; i < len(m.Buttons); i++ {
+			m.Buttons[i].Active = false
+		}
 
-			for i := 0; i < len(m.Buttons); i++ {
-				if i == m.ButtonActive {
-					m.Buttons[i].Active = true
-				} else {
-					m.Buttons[i].Active = false
-				}
-			}
+	case a&(engine.ACTION_CONFIRM|engine.ACTION_PAUSE) != 0:
+		// Change state, start game if nothing is selected
+		m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
+		if m.ButtonActive == -1 {
+			m.Engine.State.Change(NewGame(m.Engine, m.Resource))
+		} else {
+			m.Engine.State.Change(m.Buttons[m.ButtonActive].State)
+		}
 
-			break
+	case a&engine.ACTION_MENU_UP != 0:
+		m.Move(-1)
 
+	case a&engine.ACTION_MENU_DOWN != 0:
+		m.Move(1)
+	}
+}
+
+// Changes active button
+func (m *Menu) Move(dir int) {
+	if m.ButtonActive == -1 {
+		if dir < 0 {
+			m.ButtonActive = len(m.Buttons) - 1
+		} else {
+			m.ButtonActive = 0
 		}
+	} else {
+		m.ButtonActive = (m.ButtonActive + dir + len(m.Buttons)) % len(m.Buttons)
+	}
 
-	default:
-		break
+	// Set active button
+	for i := 0; i < len(m.Buttons); i++ {
+		m.Buttons[i].Active = i == m.ButtonActive
 	}
 }
 
 // Updates menu
 func (m *Menu) Update() {
 	// Don't update if timer is paused
-	if engine.Paused {
+	if m.Engine.Clock.Paused {
 		return
 	}
 
@@ -3,9 +3,9 @@ package game
 
 import (
 	"math"
+	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
-	"github.com/veandco/go-sdl2/sdl_mixer"
 
 	"github.com/gen2brain/vov/src/engine"
 	"github.com/gen2brain/vov/src/system/rumble"
@@ -34,8 +34,10 @@ func NewOptions(e *engine.Engine, r *engine.Resource) (m *Options) {
 	m.Engine = e
 	m.Resource = r
 
-	m.Fog = NewFog(e, r)
-	m.Dust = NewDust(e)
+	rnd := NewRandom(time.Now().UTC().UnixNano())
+
+	m.Fog = NewFog(e, r, rnd)
+	m.Dust = NewDust(e, rnd)
 
 	return
 }
@@ -50,7 +52,7 @@ func (m *Options) OnInit() bool {
 	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.MusicText, m.Resource.MusicTextHi, nil, m.Engine.Cfg.MusicEnabled))
 	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.SoundsText, m.Resource.SoundsTextHi, nil, m.Engine.Cfg.SoundsEnabled))
 
-	if m.Engine.Joystick != nil {
+	if m.Engine.Accelerometer {
 		m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.AccelerometerText, m.Resource.AccelerometerTextHi, nil, m.Engine.Cfg.AccelerometerEnabled))
 	}
 
@@ -59,6 +61,9 @@ func (m *Options) OnInit() bool {
 	}
 
 	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.ShowFpsText, m.Resource.ShowFpsTextHi, nil, m.Engine.Cfg.ShowFps))
+	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.GhostText, m.Resource.GhostTextHi, nil, m.Engine.Cfg.GhostEnabled))
+	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.StickText, m.Resource.StickTextHi, nil, m.Engine.Cfg.VirtualStick))
+	m.Buttons = append(m.Buttons, NewButton(m.Engine, m.Resource.ControlsText, m.Resource.ControlsTextHi, NewControls(m.Engine, m.Resource), false))
 
 	m.ButtonActive = -1
 
@@ -66,7 +71,7 @@ func (m *Options) OnInit() bool {
 	m.NoText = NewSprite(m.Engine, m.Resource.NoText)
 
 	// Play menu music
-	if !mix.PlayingMusic() {
+	if !m.Resource.PlayingMusic(m.Resource.MusicMenu) {
 		m.Resource.PlayMusic(m.Resource.MusicMenu, -1)
 	}
 
@@ -75,7 +80,7 @@ func (m *Options) OnInit() bool {
 
 // Quits game state
 func (m *Options) OnQuit() bool {
-	m.Engine.Cfg.Save()
+	m.Engine.SaveConfig()
 	return true
 }
 
@@ -86,7 +91,7 @@ func (m *Options) String() string {
 
 // Handles input events
 func (m *Options) HandleEvents() {
-	if engine.Paused {
+	if m.Engine.Clock.Paused {
 		event := sdl.WaitEvent()
 		if event != nil {
 			m.HandleEvent(event)
@@ -100,59 +105,19 @@ func (m *Options) HandleEvents() {
 
 // Handles input event
 func (m *Options) HandleEvent(event sdl.Event) {
+	// Handle mapped actions
+	if a, pressed := m.Engine.Input.Actions(event); a != 0 {
+		if pressed {
+			m.HandleAction(a)
+		}
+		return
+	}
+
 	switch t := event.(type) {
 	case *sdl.QuitEvent:
 		// Handle quit event
 		m.Engine.Quit()
 
-	case *sdl.KeyDownEvent:
-
-		if t.Keysym.Scancode == sdl.SCANCODE_ESCAPE || t.Keysym.Scancode == sdl.SCANCODE_AC_BACK {
-			// Change state on back/escape
-			m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-			m.Engine.State.Change(NewMenu(m.Engine, m.Resource))
-		} else if (t.Keysym.Mod&sdl.KMOD_ALT != 0 && t.Keysym.Scancode == sdl.SCANCODE_RETURN) || t.Keysym.Scancode == sdl.SCANCODE_F11 {
-			// Fullscreen
-			m.Engine.Fullscreen()
-		} else if t.Keysym.Scancode == sdl.SCANCODE_RETURN {
-			// Change state on enter
-			if m.ButtonActive != -1 {
-				m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-				m.Buttons[m.ButtonActive].Selected = !m.Buttons[m.ButtonActive].Selected
-				m.UpdateConfig()
-			}
-		} else if t.Keysym.Scancode == sdl.SCANCODE_UP || t.Keysym.Scancode == sdl.SCANCODE_DOWN {
-			// Change active button on UP/DOWN
-			if t.Keysym.Scancode == sdl.SCANCODE_UP {
-				if m.ButtonActive == -1 {
-					m.ButtonActive = len(m.Buttons) - 1
-				} else {
-					m.ButtonActive--
-					if m.ButtonActive == -1 {
-						m.ButtonActive = len(m.Buttons) - 1
-					}
-				}
-			} else if t.Keysym.Scancode == sdl.SCANCODE_DOWN {
-				if m.ButtonActive == -1 {
-					m.ButtonActive = 0
-				} else {
-					m.ButtonActive++
-					if m.ButtonActive == len(m.Buttons) {
-						m.ButtonActive = 0
-					}
-				}
-			}
-
-			// Set active button
-			for i := 0; i < len(m.Buttons); i++ {
-				if i == m.ButtonActive {
-					m.Buttons[i].Active = true
-				} else {
-					m.Buttons[i].Active = false
-				}
-			}
-		}
-
 	case *sdl.MouseMotionEvent:
 		// Highlight button on hover
 		point := sdl.Point{t.X, t.Y}
@@ -173,9 +138,8 @@ func (m *Options) HandleEvent(event sdl.Event) {
 			point := sdl.Point{t.X, t.Y}
 			for i := 0; i < len(m.Buttons); i++ {
 				if point.InRect(m.Buttons[i].Image.Rect()) {
-					m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-					m.Buttons[i].Selected = !m.Buttons[i].Selected
-					m.UpdateConfig()
+					m.Activate(i)
+					break
 				}
 			}
 		}
@@ -191,10 +155,8 @@ func (m *Options) HandleEvent(event sdl.Event) {
 		if t.Type == sdl.FINGERDOWN {
 			for i := 0; i < len(m.Buttons); i++ {
 				if point.InRect(m.Buttons[i].Image.Rect()) {
-					m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
 					m.Buttons[i].Clicked = true
-					m.Buttons[i].Selected = !m.Buttons[i].Selected
-					m.UpdateConfig()
+					m.Activate(i)
 				} else {
 					m.Buttons[i].Clicked = false
 				}
@@ -212,63 +174,64 @@ func (m *Options) HandleEvent(event sdl.Event) {
 			m.Engine.CloseController()
 		}
 
-	case *sdl.ControllerButtonEvent:
-		// Controller buttons
-		if t.Type == sdl.CONTROLLERBUTTONDOWN {
-			if t.Button == sdl.CONTROLLER_BUTTON_BACK {
-				m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-				m.Engine.State.Change(NewMenu(m.Engine, m.Resource))
-			} else if t.Button == sdl.CONTROLLER_BUTTON_A {
-				if m.ButtonActive != -1 {
-					m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-					m.Buttons[m.ButtonActive].Selected = !m.Buttons[m.ButtonActive].Selected
-					m.UpdateConfig()
-				}
-			} else if t.Button == sdl.CONTROLLER_BUTTON_B || t.Button == sdl.CONTROLLER_BUTTON_BACK {
-				m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
-				m.Engine.State.Change(NewMenu(m.Engine, m.Resource))
-			}
+	default:
+		break
+	}
+}
+
+// Handles input action
+func (m *Options) HandleAction(a engine.Action) {
+	switch {
+	case a&engine.ACTION_TOGGLE_FULLSCREEN != 0:
+		m.Engine.Fullscreen()
+
+	case a&engine.ACTION_BACK != 0:
+		// Change state on back
+		m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
+		m.Engine.State.Change(NewMenu(m.Engine, m.Resource))
+
+	case a&engine.ACTION_CONFIRM != 0:
+		// Toggle active button
+		if m.ButtonActive != -1 {
+			m.Activate(m.ButtonActive)
 		}
 
-	case *sdl.ControllerAxisEvent:
-		// Controller axises
-		switch t.Axis {
-		case sdl.CONTROLLER_AXIS_LEFTY:
-			// Change active button on UP/DOWN
-			if t.Value < 0 {
-				if m.ButtonActive == -1 {
-					m.ButtonActive = len(m.Buttons) - 1
-				} else {
-					m.ButtonActive--
-					if m.ButtonActive == -1 {
-						m.ButtonActive = len(m.Buttons) - 1
-					}
-				}
-			} else if t.Value > 0 {
-				if m.ButtonActive == -1 {
-					m.ButtonActive = 0
-				} else {
-					m.ButtonActive++
-					if m.ButtonActive == len(m.Buttons) {
-						m.ButtonActive = 0
-					}
-				}
-			}
+	case a&engine.ACTION_MENU_UP != 0:
+		m.Move(-1)
 
-			for i := 0; i < len(m.Buttons); i++ {
-				if i == m.ButtonActive {
-					m.Buttons[i].Active = true
-				} else {
-					m.Buttons[i].Active = false
-				}
-			}
+	case a&engine.ACTION_MENU_DOWN != 0:
+		m.Move(1)
+	}
+}
 
-			break
+// Toggles button, or changes state if button has one
+func (m *Options) Activate(i int) {
+	m.Resource.PlaySound(m.Resource.SoundClick, -1, 0)
+
+	if m.Buttons[i].State != nil {
+		m.Engine.State.Change(m.Buttons[i].State)
+		return
+	}
 
+	m.Buttons[i].Selected = !m.Buttons[i].Selected
+	m.UpdateConfig()
+}
+
+// Changes active button
+func (m *Options) Move(dir int) {
+	if m.ButtonActive == -1 {
+		if dir < 0 {
+			m.ButtonActive = len(m.Buttons) - 1
+		} else {
+			m.ButtonActive = 0
 		}
+	} else {
+		m.ButtonActive = (m.ButtonActive + dir + len(m.Buttons)) % len(m.Buttons)
+	}
 
-	default:
-		break
+	// Set active button
+	for i := 0; i < len(m.Buttons); i++ {
+		m.Buttons[i].Active = i == m.ButtonActive
 	}
 }
 
@@ -288,13 +251,19 @@ func (m *Options) UpdateConfig() {
 		case m.Resource.ShowFpsText:
 			m.Engine.Cfg.ShowFps = m.Buttons[i].Selected
 
+		case m.Resource.GhostText:
+			m.Engine.Cfg.GhostEnabled = m.Buttons[i].Selected
+
+		case m.Resource.StickText:
+			m.Engine.Cfg.VirtualStick = m.Buttons[i].Selected
+
 		case m.Resource.MusicText:
 			m.Engine.Cfg.MusicEnabled = m.Buttons[i].Selected
 
-			if m.Buttons[i].Selected && !mix.PlayingMusic() {
+			if m.Buttons[i].Selected && !m.Resource.PlayingMusic(m.Resource.MusicMenu) {
 				m.Resource.PlayMusic(m.Resource.MusicMenu, -1)
-			} else if !m.Buttons[i].Selected && mix.PlayingMusic() {
-				mix.HaltMusic()
+			} else if !m.Buttons[i].Selected {
+				m.Resource.HaltMusic()
 			}
 		}
 	}
@@ -303,7 +272,7 @@ func (m *Options) UpdateConfig() {
 // Updates menu
 func (m *Options) Update() {
 	// Don't update if timer is paused
-	if engine.Paused {
+	if m.Engine.Clock.Paused {
 		return
 	}
 
@@ -340,6 +309,10 @@ func (m *Options) Draw() {
 	for i := 0; i < len(m.Buttons); i++ {
 		m.Buttons[i].Draw()
 
+		if m.Buttons[i].State != nil {
+			continue
+		}
+
 		if m.Buttons[i].Selected {
 			m.YesText.X = m.Buttons[i].Image.X + m.Buttons[i].Image.Width + m.YesText.Width/2
 			m.YesText.Y = m.Buttons[i].Image.Y
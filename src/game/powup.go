@@ -35,18 +35,19 @@ func (r *Powups) Init() {
 	r.Powups = make([]*Sprite, r.Engine.Cfg.MaxPowups)
 
 	r.Glow = NewSprite(r.Game.Engine, r.Game.Resource.PowupGlow)
-	r.Glow.Texture.SetBlendMode(sdl.BLENDMODE_ADD)
 	r.Glow.Width /= float64(r.Engine.Cfg.NFrames)
 
+	if r.Glow.Texture != nil {
+		r.Glow.Texture.SetBlendMode(sdl.BLENDMODE_ADD)
+	}
+
 	for i := 0; i < r.Engine.Cfg.MaxPowups; i++ {
-		pow := NewSprite(r.Engine, r.Game.Resource.Powup)
+		pow := NewSurfaceSprite(r.Engine, r.Game.Resource.Powup, r.Game.Resource.PowupSurf)
 		pow.Width /= float64(6)
 
-		pow.Surface = r.Game.Resource.PowupSurf
-
 		pow.Type = POWUP
 		pow.Flags = MOVE | DRAW | COLLIDE
-		pow.State = rnd(0, 6)
+		pow.State = r.Game.Rand.rnd(0, 6)
 
 		pow.Exp1 = NewSprite(r.Engine, r.Game.Resource.Explosion2)
 
@@ -105,7 +106,7 @@ func (r *Powups) New() {
 		return
 	}
 
-	i := urnd() % uint32(r.Engine.Cfg.MaxPowups)
+	i := r.Game.Rand.urnd() % uint32(r.Engine.Cfg.MaxPowups)
 
 	if r.Powups[i].Active {
 		return
@@ -113,35 +114,35 @@ func (r *Powups) New() {
 
 	r.Sides()
 
-	direction := rnd(0, 5)
+	direction := r.Game.Rand.rnd(0, 5)
 	r.Powups[i].X = 0
 	r.Powups[i].Y = 0
 
 	switch direction {
 	case RIGHT:
 		r.Powups[i].X = r.Engine.Cfg.WinWidth
-		r.Powups[i].Y = frnd() * (r.Engine.Cfg.WinHeight + r.Powups[i].Height)
+		r.Powups[i].Y = r.Game.Rand.frnd() * (r.Engine.Cfg.WinHeight + r.Powups[i].Height)
 
-		r.Powups[i].DX = -weightedRndRange(r.SpeedMin[direction], r.SpeedMax[direction]) + r.Engine.ScreenDX
-		r.Powups[i].DY = r.Engine.Cfg.RDY * crnd()
+		r.Powups[i].DX = -r.Game.Rand.weightedRndRange(r.SpeedMin[direction], r.SpeedMax[direction]) + r.Engine.ScreenDX
+		r.Powups[i].DY = r.Engine.Cfg.RDY * r.Game.Rand.crnd()
 	case LEFT:
 		r.Powups[i].X = -r.Powups[i].Width
-		r.Powups[i].Y = frnd() * (r.Engine.Cfg.WinHeight + r.Powups[i].Height)
+		r.Powups[i].Y = r.Game.Rand.frnd() * (r.Engine.Cfg.WinHeight + r.Powups[i].Height)
 
-		r.Powups[i].DX = weightedRndRange(r.SpeedMin[direction], r.SpeedMax[direction]) + r.Engine.ScreenDX
-		r.Powups[i].DY = r.Engine.Cfg.RDY * crnd()
+		r.Powups[i].DX = r.Game.Rand.weightedRndRange(r.SpeedMin[direction], r.SpeedMax[direction]) + r.Engine.ScreenDX
+		r.Powups[i].DY = r.Engine.Cfg.RDY * r.Game.Rand.crnd()
 	case DOWN:
-		r.Powups[i].X = (frnd() * (r.Engine.Cfg.WinWidth + r.Powups[i].Width)) - r.Powups[i].Width
+		r.Powups[i].X = (r.Game.Rand.frnd() * (r.Engine.Cfg.WinWidth + r.Powups[i].Width)) - r.Powups[i].Width
 		r.Powups[i].Y = r.Engine.Cfg.WinHeight
 
-		r.Powups[i].DX = r.Engine.Cfg.RDX * crnd()
-		r.Powups[i].DY = -weightedRndRange(r.SpeedMin[direction], r.SpeedMax[direction]) + r.Engine.ScreenDY
+		r.Powups[i].DX = r.Engine.Cfg.RDX * r.Game.Rand.crnd()
+		r.Powups[i].DY = -r.Game.Rand.weightedRndRange(r.SpeedMin[direction], r.SpeedMax[direction]) + r.Engine.ScreenDY
 	case UP:
-		r.Powups[i].X = (frnd() * (r.Engine.Cfg.WinWidth + r.Powups[i].Width)) - r.Powups[i].Width
+		r.Powups[i].X = (r.Game.Rand.frnd() * (r.Engine.Cfg.WinWidth + r.Powups[i].Width)) - r.Powups[i].Width
 		r.Powups[i].Y = -r.Powups[i].Height
 
-		r.Powups[i].DX = r.Engine.Cfg.RDX * crnd()
-		r.Powups[i].DY = weightedRndRange(r.SpeedMin[direction], r.SpeedMax[direction]) + r.Engine.ScreenDY
+		r.Powups[i].DX = r.Engine.Cfg.RDX * r.Game.Rand.crnd()
+		r.Powups[i].DY = r.Game.Rand.weightedRndRange(r.SpeedMin[direction], r.SpeedMax[direction]) + r.Engine.ScreenDY
 	}
 
 	r.Powups[i].Active = true
@@ -171,13 +172,16 @@ func (r *Powups) Collisions() {
 
 // Updates powups
 func (r *Powups) Update() {
-	r.Timeout += int(r.Engine.FrameDelta)
+	r.Timeout += int(r.Engine.StepDelta)
 
 	for i := 0; i < len(r.Powups); i++ {
 		if r.Powups[i].Active {
 			// Move powup
 			r.Powups[i].Update()
 
+			// Update explosion
+			r.Powups[i].UpdateExplosion()
+
 			// Clip powup
 			x := 100.0
 			if r.Powups[i].X < -r.Powups[i].Width || r.Powups[i].X >= r.Engine.Cfg.WinWidth+x || r.Powups[i].Y < -(r.Powups[i].Height+x) || r.Powups[i].Y >= r.Engine.Cfg.WinHeight+x {
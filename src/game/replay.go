@@ -0,0 +1,512 @@
+// VoV game
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gen2brain/vov/src/engine"
+	"github.com/gen2brain/vov/src/system/home"
+)
+
+// Replay file magic and version
+const (
+	replayMagic   = "VoVR"
+	replayVersion = 3
+	replayExt     = ".vovr"
+
+	// Ticks between ship trajectory samples
+	replayTrackTicks = 4
+)
+
+// Replay event types
+const (
+	EventDirection = iota
+	EventPause
+)
+
+// Replay input event
+type ReplayEvent struct {
+	// Simulation tick
+	Tick int
+	// Event type
+	Type int
+	// Direction states bitmask
+	Mask uint8
+	// Analog thrust levels of directions
+	Power [4]uint8
+}
+
+// Ship trajectory sample, in world coordinates
+type ReplayPoint struct {
+	X       int32
+	Y       int32
+	Visible bool
+}
+
+// Config values that affect simulation
+type ReplayConfig struct {
+	FixedStep             bool
+	StepLength            int
+	GameSpeed             float64
+	BarrierSpeed          float64
+	Bounciness            float64
+	ThrusterStrength      float64
+	DotMassUnit           float64
+	NRocks                int
+	MaxRocks              int
+	MaxPowups             int
+	PowupsTimeout         int
+	PowupStateTimeout     float64
+	NFrames               uint32
+	InitialRocks          int
+	FinalRocks            int
+	KH                    float64
+	KV                    float64
+	RDX                   float64
+	RDY                   float64
+	MaxShipDots           int
+	NShipDotsArray        int
+	MaxBangDots           int
+	NBangDotsArray        int
+	EngineDots            int
+	DeadPauseLength       float64
+	InvinciblePauseLength float64
+	GameOverLength        float64
+	WinWidth              float64
+	WinHeight             float64
+}
+
+// Gets values from config
+func (rc *ReplayConfig) Get(c *engine.Config) {
+	rc.FixedStep = c.FixedStep
+	rc.StepLength = c.StepLength
+	rc.GameSpeed = c.GameSpeed
+	rc.BarrierSpeed = c.BarrierSpeed
+	rc.Bounciness = c.Bounciness
+	rc.ThrusterStrength = c.ThrusterStrength
+	rc.DotMassUnit = c.DotMassUnit
+	rc.NRocks = c.NRocks
+	rc.MaxRocks = c.MaxRocks
+	rc.MaxPowups = c.MaxPowups
+	rc.PowupsTimeout = c.PowupsTimeout
+	rc.PowupStateTimeout = c.PowupStateTimeout
+	rc.NFrames = c.NFrames
+	rc.InitialRocks = c.InitialRocks
+	rc.FinalRocks = c.FinalRocks
+	rc.KH = c.KH
+	rc.KV = c.KV
+	rc.RDX = c.RDX
+	rc.RDY = c.RDY
+	rc.MaxShipDots = c.MaxShipDots
+	rc.NShipDotsArray = c.NShipDotsArray
+	rc.MaxBangDots = c.MaxBangDots
+	rc.NBangDotsArray = c.NBangDotsArray
+	rc.EngineDots = c.EngineDots
+	rc.DeadPauseLength = c.DeadPauseLength
+	rc.InvinciblePauseLength = c.InvinciblePauseLength
+	rc.GameOverLength = c.GameOverLength
+	rc.WinWidth = c.WinWidth
+	rc.WinHeight = c.WinHeight
+}
+
+// Gets values that are applied on tuning reload
+func (rc *ReplayConfig) GetLive(c *engine.Config) {
+	rc.GameSpeed = c.GameSpeed
+	rc.BarrierSpeed = c.BarrierSpeed
+	rc.Bounciness = c.Bounciness
+	rc.ThrusterStrength = c.ThrusterStrength
+	rc.DotMassUnit = c.DotMassUnit
+	rc.PowupsTimeout = c.PowupsTimeout
+	rc.PowupStateTimeout = c.PowupStateTimeout
+	rc.InitialRocks = c.InitialRocks
+	rc.FinalRocks = c.FinalRocks
+	rc.KH = c.KH
+	rc.KV = c.KV
+	rc.RDX = c.RDX
+	rc.RDY = c.RDY
+	rc.DeadPauseLength = c.DeadPauseLength
+	rc.InvinciblePauseLength = c.InvinciblePauseLength
+	rc.GameOverLength = c.GameOverLength
+}
+
+// Sets values to config, window dimensions are set with engine
+func (rc *ReplayConfig) Set(c *engine.Config) {
+	c.FixedStep = rc.FixedStep
+	c.StepLength = rc.StepLength
+	c.GameSpeed = rc.GameSpeed
+	c.BarrierSpeed = rc.BarrierSpeed
+	c.Bounciness = rc.Bounciness
+	c.ThrusterStrength = rc.ThrusterStrength
+	c.DotMassUnit = rc.DotMassUnit
+	c.NRocks = rc.NRocks
+	c.MaxRocks = rc.MaxRocks
+	c.MaxPowups = rc.MaxPowups
+	c.PowupsTimeout = rc.PowupsTimeout
+	c.PowupStateTimeout = rc.PowupStateTimeout
+	c.NFrames = rc.NFrames
+	c.InitialRocks = rc.InitialRocks
+	c.FinalRocks = rc.FinalRocks
+	c.KH = rc.KH
+	c.KV = rc.KV
+	c.RDX = rc.RDX
+	c.RDY = rc.RDY
+	c.MaxShipDots = rc.MaxShipDots
+	c.NShipDotsArray = rc.NShipDotsArray
+	c.MaxBangDots = rc.MaxBangDots
+	c.NBangDotsArray = rc.NBangDotsArray
+	c.EngineDots = rc.EngineDots
+	c.DeadPauseLength = rc.DeadPauseLength
+	c.InvinciblePauseLength = rc.InvinciblePauseLength
+	c.GameOverLength = rc.GameOverLength
+}
+
+// Replay header
+type ReplayHeader struct {
+	Version int
+
+	// Game seed
+	Seed int64
+	// Resource seed, used for rocks
+	RocksSeed int64
+
+	// Unix time of recording in milliseconds
+	Date int64
+
+	// Final score and number of ticks
+	Score int
+	Ticks int
+
+	Config ReplayConfig
+}
+
+// Replay structure
+type Replay struct {
+	Header ReplayHeader
+	Events []ReplayEvent
+
+	// Ship trajectory, sampled every replayTrackTicks
+	Track []ReplayPoint
+
+	// Last recorded direction mask and thrust levels
+	Mask  uint8
+	Power [4]uint8
+
+	// Playback position
+	Pos int
+}
+
+// Returns new replay for game
+func NewReplay(g *Game) (r *Replay) {
+	r = &Replay{}
+	r.Header.Version = replayVersion
+	r.Header.Seed = g.Seed
+	r.Header.RocksSeed = g.Resource.Seed
+	r.Header.Date = time.Now().UnixNano() / int64(time.Millisecond)
+	r.Header.Config.Get(g.Cfg)
+	return
+}
+
+// Records event at tick
+func (r *Replay) Record(tick, typ int, mask uint8) {
+	r.Events = append(r.Events, ReplayEvent{tick, typ, mask, [4]uint8{}})
+}
+
+// Records direction mask and thrust levels if changed
+func (r *Replay) RecordMask(tick int, mask uint8, power [4]uint8) {
+	if mask == r.Mask && power == r.Power {
+		return
+	}
+
+	r.Mask = mask
+	r.Power = power
+	r.Events = append(r.Events, ReplayEvent{tick, EventDirection, mask, power})
+}
+
+// Records ship position at tick
+func (r *Replay) RecordTrack(tick int, x, y float64, visible bool) {
+	if tick%replayTrackTicks != 0 {
+		return
+	}
+
+	r.Track = append(r.Track, ReplayPoint{int32(x), int32(y), visible})
+}
+
+// Returns interpolated ship position at tick, ok is false after end of trajectory
+func (r *Replay) TrackAt(tick int) (x, y float64, visible, ok bool) {
+	i := tick / replayTrackTicks
+	if i < 0 || i >= len(r.Track) {
+		return
+	}
+
+	a := r.Track[i]
+	x, y, visible, ok = float64(a.X), float64(a.Y), a.Visible, true
+
+	if i+1 < len(r.Track) {
+		b := r.Track[i+1]
+		f := float64(tick%replayTrackTicks) / replayTrackTicks
+		x += float64(b.X-a.X) * f
+		y += float64(b.Y-a.Y) * f
+	}
+
+	return
+}
+
+// Returns direction mask at tick, tick must not decrease between calls.
+// Thrust levels at tick are stored in Power
+func (r *Replay) MaskAt(tick int) uint8 {
+	for r.Pos < len(r.Events) && r.Events[r.Pos].Tick <= tick {
+		if r.Events[r.Pos].Type == EventDirection {
+			r.Mask = r.Events[r.Pos].Mask
+			r.Power = r.Events[r.Pos].Power
+		}
+		r.Pos++
+	}
+
+	return r.Mask
+}
+
+// Rewinds playback to start
+func (r *Replay) Rewind() {
+	r.Pos = 0
+	r.Mask = 0
+	r.Power = [4]uint8{}
+}
+
+// Checks if all events are played
+func (r *Replay) Done() bool {
+	return r.Pos >= len(r.Events)
+}
+
+// Encodes replay, events are stored as varint tick delta followed by one byte,
+// low nibble is direction mask, EventPause is stored as 0x10.
+// Bit 0x20 marks analog thrust, followed by four level bytes.
+// Trajectory is stored as varint position deltas followed by visibility byte
+func (r *Replay) MarshalBinary() ([]byte, error) {
+	js, err := json.Marshal(r.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	tmp := make([]byte, binary.MaxVarintLen64)
+
+	buf.WriteString(replayMagic)
+
+	n := binary.PutUvarint(tmp, uint64(len(js)))
+	buf.Write(tmp[:n])
+	buf.Write(js)
+
+	n = binary.PutUvarint(tmp, uint64(len(r.Events)))
+	buf.Write(tmp[:n])
+
+	last := 0
+	for _, ev := range r.Events {
+		n = binary.PutUvarint(tmp, uint64(ev.Tick-last))
+		buf.Write(tmp[:n])
+		last = ev.Tick
+
+		if ev.Type == EventPause {
+			buf.WriteByte(0x10)
+		} else if ev.Power != [4]uint8{} {
+			buf.WriteByte(ev.Mask&0x0f | 0x20)
+			buf.Write(ev.Power[:])
+		} else {
+			buf.WriteByte(ev.Mask & 0x0f)
+		}
+	}
+
+	n = binary.PutUvarint(tmp, uint64(len(r.Track)))
+	buf.Write(tmp[:n])
+
+	var x, y int32
+	for _, p := range r.Track {
+		n = binary.PutVarint(tmp, int64(p.X-x))
+		buf.Write(tmp[:n])
+		n = binary.PutVarint(tmp, int64(p.Y-y))
+		buf.Write(tmp[:n])
+		x, y = p.X, p.Y
+
+		if p.Visible {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decodes replay
+func (r *Replay) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(replayMagic)) {
+		return errors.New("not a replay file")
+	}
+
+	buf := bytes.NewReader(data[len(replayMagic):])
+
+	size, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return err
+	}
+
+	if size > uint64(buf.Len()) {
+		return errors.New("invalid header length")
+	}
+
+	js := make([]byte, size)
+	if _, err = io.ReadFull(buf, js); err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(js, &r.Header)
+	if err != nil {
+		return err
+	}
+
+	if r.Header.Version != replayVersion {
+		return fmt.Errorf("unsupported replay version %d", r.Header.Version)
+	}
+
+	if r.Header.Config.StepLength <= 0 {
+		return errors.New("invalid step length")
+	}
+
+	// Every event takes at least two bytes
+	count, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return err
+	}
+
+	if count > uint64(buf.Len())/2 {
+		return errors.New("invalid event count")
+	}
+
+	r.Events = make([]ReplayEvent, 0, count)
+
+	tick := 0
+	for i := uint64(0); i < count; i++ {
+		delta, err := binary.ReadUvarint(buf)
+		if err != nil {
+			return err
+		}
+
+		b, err := buf.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		tick += int(delta)
+
+		if b == 0x10 {
+			r.Events = append(r.Events, ReplayEvent{tick, EventPause, 0, [4]uint8{}})
+		} else {
+			ev := ReplayEvent{tick, EventDirection, b & 0x0f, [4]uint8{}}
+			if b&0x20 != 0 {
+				if _, err = io.ReadFull(buf, ev.Power[:]); err != nil {
+					return err
+				}
+			}
+
+			r.Events = append(r.Events, ev)
+		}
+	}
+
+	// Every trajectory sample takes at least three bytes
+	count, err = binary.ReadUvarint(buf)
+	if err != nil {
+		return err
+	}
+
+	if count > uint64(buf.Len())/3 {
+		return errors.New("invalid trajectory length")
+	}
+
+	r.Track = make([]ReplayPoint, 0, count)
+
+	var x, y int32
+	for i := uint64(0); i < count; i++ {
+		dx, err := binary.ReadVarint(buf)
+		if err != nil {
+			return err
+		}
+
+		dy, err := binary.ReadVarint(buf)
+		if err != nil {
+			return err
+		}
+
+		b, err := buf.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		x += int32(dx)
+		y += int32(dy)
+
+		r.Track = append(r.Track, ReplayPoint{x, y, b != 0})
+	}
+
+	r.Rewind()
+
+	return nil
+}
+
+// Saves replay to replays directory, returns file name
+func (r *Replay) Save() (string, error) {
+	dir := replayDir()
+	if _, err := os.Stat(dir); err != nil {
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	date := time.Unix(0, r.Header.Date*int64(time.Millisecond)).UTC()
+	name := date.Format("20060102-150405.000") + replayExt
+
+	err = ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// Loads replay from file, name is relative to replays directory if not absolute
+func LoadReplay(name string) (*Replay, error) {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(replayDir(), name)
+	}
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replay{}
+	err = r.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Returns replays directory
+func replayDir() string {
+	return filepath.Join(home.Dir(), ".vov", "replays")
+}
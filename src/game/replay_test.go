@@ -0,0 +1,142 @@
+// VoV game
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func testReplay() *Replay {
+	r := &Replay{}
+	r.Header.Version = replayVersion
+	r.Header.Seed = 42
+	r.Header.RocksSeed = 7
+	r.Header.Date = 1500000000123
+	r.Header.Score = 1234
+	r.Header.Ticks = 900
+	r.Header.Config.StepLength = 16
+	r.Header.Config.GameSpeed = 1
+
+	r.RecordMask(0, 0x01, [4]uint8{})
+	r.RecordMask(10, 0x03, [4]uint8{255, 128, 0, 0})
+	r.Record(20, EventPause, 0)
+	r.Record(300, EventPause, 0)
+	r.RecordMask(301, 0x00, [4]uint8{})
+
+	for tick := 0; tick < 40; tick++ {
+		r.RecordTrack(tick, float64(tick*3), float64(-tick), tick%8 != 0)
+	}
+
+	return r
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	r := testReplay()
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Replay{}
+	if err = d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(r.Header, d.Header) {
+		t.Errorf("header %+v, want %+v", d.Header, r.Header)
+	}
+
+	if !reflect.DeepEqual(r.Events, d.Events) {
+		t.Errorf("events %v, want %v", d.Events, r.Events)
+	}
+
+	if !reflect.DeepEqual(r.Track, d.Track) {
+		t.Errorf("track %v, want %v", d.Track, r.Track)
+	}
+}
+
+func TestReplayMaskAt(t *testing.T) {
+	r := testReplay()
+	r.Rewind()
+
+	tests := []struct {
+		tick  int
+		mask  uint8
+		power [4]uint8
+	}{
+		{0, 0x01, [4]uint8{}},
+		{9, 0x01, [4]uint8{}},
+		{10, 0x03, [4]uint8{255, 128, 0, 0}},
+		{300, 0x03, [4]uint8{255, 128, 0, 0}},
+		{301, 0x00, [4]uint8{}},
+	}
+
+	for _, tt := range tests {
+		if mask := r.MaskAt(tt.tick); mask != tt.mask || r.Power != tt.power {
+			t.Errorf("tick %d: mask %#x power %v, want %#x %v", tt.tick, mask, r.Power, tt.mask, tt.power)
+		}
+	}
+
+	if !r.Done() {
+		t.Error("replay not done after last event")
+	}
+}
+
+func TestReplayCorrupt(t *testing.T) {
+	data, err := testReplay().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every truncation must fail without panic
+	for i := 0; i < len(data); i++ {
+		if err := (&Replay{}).UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("truncated to %d bytes: no error", i)
+		}
+	}
+
+	// Flipped bytes must not panic
+	for i := len(replayMagic); i < len(data); i++ {
+		c := append([]byte(nil), data...)
+		c[i] ^= 0xff
+		(&Replay{}).UnmarshalBinary(c)
+	}
+
+	huge := func(prefix []byte) []byte {
+		tmp := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(tmp, 1<<62)
+		return append(append(append([]byte(nil), prefix...), tmp[:n]...), 0, 0, 0, 0)
+	}
+
+	// Header length larger than data
+	if err := (&Replay{}).UnmarshalBinary(huge([]byte(replayMagic))); err == nil {
+		t.Error("huge header length: no error")
+	}
+
+	// Event count larger than data
+	header := data[:bytes.Index(data, []byte("}"))+1]
+	if err := (&Replay{}).UnmarshalBinary(huge(header)); err == nil {
+		t.Error("huge event count: no error")
+	}
+
+	if err := (&Replay{}).UnmarshalBinary([]byte("nope")); err == nil {
+		t.Error("wrong magic: no error")
+	}
+}
+
+func TestReplayVersion(t *testing.T) {
+	r := testReplay()
+	r.Header.Version = replayVersion - 1
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&Replay{}).UnmarshalBinary(data); err == nil {
+		t.Error("old version: no error")
+	}
+}
@@ -10,6 +10,7 @@ type Rocks struct {
 	Engine   *engine.Engine
 	Cfg      *engine.Config
 	Resource *engine.Resource
+	Rand     *Random
 
 	Rocks      []*Sprite
 	Prototypes []*Sprite
@@ -21,6 +22,9 @@ type Rocks struct {
 	NrocksIncTicks float64
 	CurrentRock    int
 
+	// Animation ticks (milliseconds)
+	Ticks uint32
+
 	Ti       [4]float64
 	Rtimers  [4]float64
 	SpeedMin [4]float64
@@ -28,11 +32,12 @@ type Rocks struct {
 }
 
 // Returns new rocks
-func NewRocks(e *engine.Engine, res *engine.Resource) (r *Rocks) {
+func NewRocks(e *engine.Engine, res *engine.Resource, rnd *Random) (r *Rocks) {
 	r = &Rocks{}
 	r.Engine = e
 	r.Cfg = e.Cfg
 	r.Resource = res
+	r.Rand = rnd
 
 	r.Nrocks = r.Cfg.InitialRocks
 	r.InitialRocks = r.Cfg.InitialRocks
@@ -46,13 +51,11 @@ func (r *Rocks) Init() {
 	r.Prototypes = make([]*Sprite, r.Cfg.NRocks)
 
 	for i := 0; i < r.Cfg.NRocks; i++ {
-		s := NewSprite(r.Engine, r.Resource.Rocks[i])
+		s := NewSurfaceSprite(r.Engine, r.Resource.Rocks[i], r.Resource.RocksSurf[i])
 
 		s.Type = ROCK
 		s.Flags = MOVE | DRAW | COLLIDE
 
-		s.Surface = r.Resource.RocksSurf[i]
-
 		s.Exp1 = NewSprite(r.Engine, r.Resource.Explosion1)
 		s.Exp2 = NewSprite(r.Engine, r.Resource.Explosion2)
 
@@ -70,6 +73,7 @@ func (r *Rocks) Reset() {
 	r.NrocksIncTicks = float64(2*60*20) / float64(r.FinalRocks-r.InitialRocks)
 	r.NrocksTimer = 0
 	r.CurrentRock = 0
+	r.Ticks = 0
 }
 
 // Computes the number of rocks/tick that should be coming from each side,
@@ -166,37 +170,37 @@ func (r *Rocks) New() {
 			if r.Rocks[r.CurrentRock] == nil || !r.Rocks[r.CurrentRock].Active {
 
 				p := &Sprite{}
-				*p = *r.Prototypes[urnd()%uint32(r.Cfg.NRocks)]
+				*p = *r.Prototypes[r.Rand.urnd()%uint32(r.Cfg.NRocks)]
 
 				switch i {
 				case RIGHT:
 					p.X = r.Cfg.WinWidth
-					p.Y = frnd() * (r.Cfg.WinHeight + p.Height)
+					p.Y = r.Rand.frnd() * (r.Cfg.WinHeight + p.Height)
 
-					p.DX = -weightedRndRange(r.SpeedMin[i], r.SpeedMax[i]) + r.Engine.ScreenDX
-					p.DY = r.Cfg.RDY * crnd()
+					p.DX = -r.Rand.weightedRndRange(r.SpeedMin[i], r.SpeedMax[i]) + r.Engine.ScreenDX
+					p.DY = r.Cfg.RDY * r.Rand.crnd()
 				case LEFT:
 					p.X = -p.Width
-					p.Y = frnd() * (r.Cfg.WinHeight + p.Height)
+					p.Y = r.Rand.frnd() * (r.Cfg.WinHeight + p.Height)
 
-					p.DX = weightedRndRange(r.SpeedMin[i], r.SpeedMax[i]) + r.Engine.ScreenDX
-					p.DY = r.Cfg.RDY * crnd()
+					p.DX = r.Rand.weightedRndRange(r.SpeedMin[i], r.SpeedMax[i]) + r.Engine.ScreenDX
+					p.DY = r.Cfg.RDY * r.Rand.crnd()
 				case DOWN:
-					p.X = (frnd() * (r.Cfg.WinWidth + p.Width)) - p.Width
+					p.X = (r.Rand.frnd() * (r.Cfg.WinWidth + p.Width)) - p.Width
 					p.Y = r.Cfg.WinHeight
 
-					p.DX = r.Cfg.RDX * crnd()
-					p.DY = -weightedRndRange(r.SpeedMin[i], r.SpeedMax[i]) + r.Engine.ScreenDY
+					p.DX = r.Cfg.RDX * r.Rand.crnd()
+					p.DY = -r.Rand.weightedRndRange(r.SpeedMin[i], r.SpeedMax[i]) + r.Engine.ScreenDY
 				case UP:
-					p.X = (frnd() * (r.Cfg.WinWidth + p.Width)) - p.Width
+					p.X = (r.Rand.frnd() * (r.Cfg.WinWidth + p.Width)) - p.Width
 					p.Y = -p.Height
 
-					p.DX = r.Cfg.RDX * crnd()
-					p.DY = weightedRndRange(r.SpeedMin[i], r.SpeedMax[i]) + r.Engine.ScreenDY
+					p.DX = r.Cfg.RDX * r.Rand.crnd()
+					p.DY = r.Rand.weightedRndRange(r.SpeedMin[i], r.SpeedMax[i]) + r.Engine.ScreenDY
 				}
 
 				p.Active = true
-				p.Direction = rnd(0, 2)
+				p.Direction = r.Rand.rnd(0, 2)
 				p.Life = int(p.Width * p.Height * 300)
 				p.Flags = MOVE | DRAW | COLLIDE
 
@@ -239,11 +243,19 @@ func (r *Rocks) Collisions() {
 
 // Updates rocks
 func (r *Rocks) Update() {
+	r.Ticks += r.Engine.StepDelta
+
 	for i := 0; i < r.Cfg.MaxRocks; i++ {
 		if r.Rocks[i] != nil && r.Rocks[i].Active {
 			// Move rock
 			r.Rocks[i].Update()
 
+			// Rock animation frame, used also for collisions
+			r.Rocks[i].Frame = (r.Ticks / 50) % r.Cfg.NFrames
+
+			// Update explosion
+			r.Rocks[i].UpdateExplosion()
+
 			// Clip rock
 			if r.Rocks[i].X < -r.Rocks[i].Width || r.Rocks[i].X >= r.Cfg.WinWidth || r.Rocks[i].Y < -r.Rocks[i].Height || r.Rocks[i].Y >= r.Cfg.WinHeight {
 				r.Rocks[i].Active = false
@@ -8,9 +8,9 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
-	"github.com/veandco/go-sdl2/sdl_mixer"
 
 	"github.com/gen2brain/vov/src/engine"
 	"github.com/gen2brain/vov/src/system/home"
@@ -29,6 +29,8 @@ type Score struct {
 	Width     float64
 	Height    float64
 	Formatted string
+	Replay    string
+	Date      string
 }
 
 // Scores structure
@@ -42,6 +44,21 @@ type Scores struct {
 	// Current score
 	Current int
 
+	// Replay file of current score
+	CurrentReplay string
+
+	// Selected score for replay, -1 if none
+	Selected int
+
+	// First shown score, daily leaderboard scrolls through older days
+	Offset int
+
+	// Show daily leaderboard
+	Daily bool
+
+	// Date of current daily run
+	Date string
+
 	// String from input
 	TextInput string
 
@@ -70,17 +87,29 @@ type Scores struct {
 }
 
 // Returns new scores
-func NewScores(e *engine.Engine, r *engine.Resource, score int, cont bool) (s *Scores) {
+func NewScores(e *engine.Engine, r *engine.Resource, score int, replay string, cont bool) (s *Scores) {
 	s = &Scores{}
 
 	s.Engine = e
 	s.Resource = r
 	s.Current = score
+	s.CurrentReplay = replay
 	s.Continue = cont
+	s.Selected = -1
 
-	s.Fog = NewFog(e, r)
-	s.Dust = NewDust(e)
+	rnd := NewRandom(time.Now().UTC().UnixNano())
+
+	s.Fog = NewFog(e, r, rnd)
+	s.Dust = NewDust(e, rnd)
+
+	return
+}
 
+// Returns new daily scores
+func NewDailyScores(e *engine.Engine, r *engine.Resource, score int, replay string, date string) (s *Scores) {
+	s = NewScores(e, r, score, replay, true)
+	s.Daily = true
+	s.Date = date
 	return
 }
 
@@ -89,9 +118,6 @@ func (s *Scores) OnInit() bool {
 	s.Fog.Init()
 	s.Dust.Init()
 
-	// Initialize scores
-	s.Scores = make([]Score, s.Engine.Cfg.NScores)
-
 	s.HiScoreText = NewSprite(s.Engine, s.Resource.HiScoreText)
 	s.HiScoreEnterText = NewSprite(s.Engine, s.Resource.HiScoreEnterText)
 
@@ -106,21 +132,22 @@ func (s *Scores) OnInit() bool {
 	s.LoadingText.Y = s.Engine.Cfg.WinHeight/2 - (s.LoadingText.Height / 2)
 
 	// Play music
-	if !mix.PlayingMusic() {
+	if !s.Resource.PlayingMusic(s.Resource.MusicMenu) {
 		s.Resource.PlayMusic(s.Resource.MusicMenu, -1)
 	}
 
 	// Load scores
-	if s.Exists() {
-		s.Load()
-	} else {
-		s.Default()
-	}
+	s.LoadBoard()
 
-	s.Format()
+	// Daily score is stored without name
+	if s.Daily && s.Date != "" && s.Current > 0 {
+		s.InsertDaily()
+		s.Save()
+		s.Format()
+	}
 
 	// Check score
-	if s.Loaded {
+	if s.Loaded && !s.Daily {
 		s.IsHighScore = s.HighScore()
 		if s.IsHighScore {
 			// Accept text input
@@ -147,7 +174,7 @@ func (s *Scores) String() string {
 
 // Handles input events
 func (s *Scores) HandleEvents() {
-	if engine.Paused {
+	if s.Engine.Clock.Paused {
 		event := sdl.WaitEvent()
 		if event != nil {
 			s.HandleEvent(event)
@@ -161,52 +188,45 @@ func (s *Scores) HandleEvents() {
 
 // Handles input event
 func (s *Scores) HandleEvent(event sdl.Event) {
+	// Handle mapped actions
+	if a, pressed := s.Engine.Input.Actions(event); a != 0 {
+		if pressed {
+			s.HandleAction(a)
+		}
+		return
+	}
+
 	switch t := event.(type) {
 	case *sdl.QuitEvent:
 		// Handle quit event
 		s.Engine.Quit()
 
 	case *sdl.KeyDownEvent:
-		if t.Keysym.Scancode == sdl.SCANCODE_ESCAPE || t.Keysym.Scancode == sdl.SCANCODE_AC_BACK {
-			// Change state on back/escape
-			s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
-			s.Engine.State.Change(NewMenu(s.Engine, s.Resource))
-		} else if (t.Keysym.Mod&sdl.KMOD_ALT != 0 && t.Keysym.Scancode == sdl.SCANCODE_RETURN) || t.Keysym.Scancode == sdl.SCANCODE_F11 {
-			// Fullscreen
-			s.Engine.Fullscreen()
-		} else if t.Keysym.Scancode == sdl.SCANCODE_BACKSPACE {
+		if t.Keysym.Scancode == sdl.SCANCODE_BACKSPACE {
 			// Handle backspace
 			if sdl.IsTextInputActive() && s.TextInput != "" {
 				s.TextInput = s.TextInput[:len(s.TextInput)-1]
 			}
-		} else if t.Keysym.Scancode == sdl.SCANCODE_RETURN {
-			// Stop accepting input on enter and save
-			if s.IsHighScore && sdl.IsTextInputActive() && s.TextInput != "" {
-				sdl.StopTextInput()
-
-				// Insert highscore
-				s.Insert()
-
-				// Save highscore
-				s.Save()
-
-				s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
-
-				s.Current = 0
-				s.Continue = false
-				s.IsHighScore = false
-
-				// Switch to menu state after duration
-				s.Continue = true
-				s.StateTimer = s.Engine.Cfg.ScoresLength
-			} else {
-				s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
-				s.Engine.State.Change(NewMenu(s.Engine, s.Resource))
-			}
+		} else if t.Keysym.Scancode == sdl.SCANCODE_TAB && !s.IsHighScore {
+			// Switch leaderboard
+			s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
+			s.Daily = !s.Daily
+			s.Continue = false
+			s.LoadBoard()
+		} else if t.Keysym.Scancode == sdl.SCANCODE_PAGEUP && !s.IsHighScore {
+			s.Scroll(-s.Engine.Cfg.NScores)
+		} else if t.Keysym.Scancode == sdl.SCANCODE_PAGEDOWN && !s.IsHighScore {
+			s.Scroll(s.Engine.Cfg.NScores)
 		}
 
 	case *sdl.MouseButtonEvent:
 		if t.Type == sdl.MOUSEBUTTONDOWN && t.Button == sdl.BUTTON_LEFT {
+			if i := s.ScoreAt(float64(t.X), float64(t.Y)); i >= 0 {
+				// Play replay of clicked score
+				s.Play(i)
+				break
+			}
+
 			// Change state on mouse button
 			s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
 			s.Engine.State.Change(NewMenu(s.Engine, s.Resource))
@@ -214,6 +234,12 @@ func (s *Scores) HandleEvent(event sdl.Event) {
 
 	case *sdl.TouchFingerEvent:
 		if t.Type == sdl.FINGERDOWN {
+			if i := s.ScoreAt(float64(t.X)*s.Engine.Cfg.WinWidth, float64(t.Y)*s.Engine.Cfg.WinHeight); i >= 0 {
+				// Play replay of touched score
+				s.Play(i)
+				break
+			}
+
 			// Change state on touch
 			s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
 			s.Engine.State.Change(NewMenu(s.Engine, s.Resource))
@@ -230,15 +256,6 @@ func (s *Scores) HandleEvent(event sdl.Event) {
 			s.Engine.CloseController()
 		}
 
-	case *sdl.ControllerButtonEvent:
-		// Controller buttons
-		if t.Type == sdl.CONTROLLERBUTTONDOWN {
-			if t.Button == sdl.CONTROLLER_BUTTON_B || t.Button == sdl.CONTROLLER_BUTTON_BACK {
-				s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
-				s.Engine.State.Change(NewMenu(s.Engine, s.Resource))
-			}
-		}
-
 	case *sdl.TextInputEvent:
 		// Enter name for highscore
 		if t.Type == sdl.TEXTINPUT {
@@ -253,10 +270,139 @@ func (s *Scores) HandleEvent(event sdl.Event) {
 	}
 }
 
+// Handles input action
+func (s *Scores) HandleAction(a engine.Action) {
+	switch {
+	case a&engine.ACTION_TOGGLE_FULLSCREEN != 0:
+		s.Engine.Fullscreen()
+
+	case a&engine.ACTION_BACK != 0:
+		// Change state on back
+		s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
+		s.Engine.State.Change(NewMenu(s.Engine, s.Resource))
+
+	case a&engine.ACTION_MENU_UP != 0 && !s.IsHighScore:
+		s.Select(-1)
+
+	case a&engine.ACTION_MENU_DOWN != 0 && !s.IsHighScore:
+		s.Select(1)
+
+	case a&engine.ACTION_CONFIRM != 0:
+		// Stop accepting input on enter and save
+		if s.IsHighScore && sdl.IsTextInputActive() && s.TextInput != "" {
+			sdl.StopTextInput()
+
+			// Insert highscore
+			s.Insert()
+
+			// Save highscore
+			s.Save()
+
+			s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
+
+			s.Current = 0
+			s.Continue = false
+			s.IsHighScore = false
+
+			// Switch to menu state after duration
+			s.Continue = true
+			s.StateTimer = s.Engine.Cfg.ScoresLength
+		} else if s.Selected >= 0 {
+			// Play selected replay
+			s.Play(s.Selected)
+		} else {
+			s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
+			s.Engine.State.Change(NewMenu(s.Engine, s.Resource))
+		}
+	}
+}
+
+// Loads current leaderboard
+func (s *Scores) LoadBoard() {
+	s.Selected = -1
+	s.Offset = 0
+
+	if s.Daily {
+		// One entry per day, most recent first
+		s.Scores = make([]Score, 0)
+	} else {
+		s.Scores = make([]Score, s.Engine.Cfg.NScores)
+	}
+
+	if s.Exists() {
+		s.Load()
+	} else {
+		s.Default()
+	}
+
+	s.Format()
+}
+
+// Returns number of shown scores, starting at Offset
+func (s *Scores) Count() int {
+	if n := len(s.Scores) - s.Offset; n < s.Engine.Cfg.NScores {
+		return n
+	}
+	return s.Engine.Cfg.NScores
+}
+
+// Scrolls shown scores by n, selection out of view is cleared
+func (s *Scores) Scroll(n int) {
+	offset := s.Offset + n
+	if max := len(s.Scores) - s.Engine.Cfg.NScores; offset > max {
+		offset = max
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset == s.Offset {
+		return
+	}
+
+	s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
+	s.Offset = offset
+	s.Continue = false
+
+	if s.Selected < s.Offset || s.Selected >= s.Offset+s.Count() {
+		s.Selected = -1
+	}
+}
+
+// Returns score label, name or date for daily scores
+func (s *Scores) Label(i int) string {
+	if s.Daily {
+		return s.Scores[i].Date
+	}
+	return s.Scores[i].Name
+}
+
+// Inserts or updates score of the day
+func (s *Scores) InsertDaily() {
+	for i := range s.Scores {
+		if s.Scores[i].Date == s.Date {
+			// Keep best run of the day
+			if s.Current > s.Scores[i].Time {
+				s.Scores[i].Time = s.Current
+				s.Scores[i].Replay = s.CurrentReplay
+			}
+			return
+		}
+	}
+
+	score := Score{Name: "-", Time: s.Current, Replay: s.CurrentReplay, Date: s.Date}
+	s.Scores = append([]Score{score}, s.Scores...)
+}
+
 // Adds default scores
 func (s *Scores) Default() {
+	if s.Daily {
+		s.Loaded = true
+		return
+	}
+
 	for i := 0; i < s.Engine.Cfg.NScores; i++ {
-		s.Scores[i] = Score{"-", 150000 - (i * 15000), 0, 0, 0, 0, ""}
+		s.Scores[i] = Score{"-", 150000 - (i * 15000), 0, 0, 0, 0, "", "", ""}
 	}
 
 	s.Loaded = true
@@ -284,16 +430,16 @@ func (s *Scores) HighScore() bool {
 // Formats scores and gets text dimensions
 func (s *Scores) Format() {
 	max := 0
-	for i := 0; i < s.Engine.Cfg.NScores; i++ {
+	for i := range s.Scores {
 		s.Scores[i].Formatted = formatTime(s.Scores[i].Time, true)
 
-		w, _, _ := s.Resource.FontMedium.SizeUTF8(s.Scores[i].Name)
+		w, _, _ := s.Resource.FontMedium.SizeUTF8(s.Label(i))
 		if w > max {
 			max = w
 		}
 	}
 
-	for i := 0; i < s.Engine.Cfg.NScores; i++ {
+	for i := range s.Scores {
 		w1, h1, _ := s.Resource.FontSmall.SizeUTF8("0.")
 		w2, _, _ := s.Resource.FontMedium.SizeUTF8(s.Scores[0].Formatted)
 		s.Scores[i].Width, s.Scores[i].Height = float64(w1+w2+max), float64(h1)
@@ -317,13 +463,75 @@ func (s *Scores) Insert() {
 
 	s.Scores[rank].Time = s.Current
 	s.Scores[rank].Name = s.TextInput
+	s.Scores[rank].Replay = s.CurrentReplay
 
 	s.Format()
 }
 
+// Selects next score with replay in direction, scrolls to keep it shown
+func (s *Scores) Select(dir int) {
+	start := s.Selected + dir
+	if s.Selected < 0 && dir > 0 {
+		start = s.Offset
+	}
+
+	for i := start; i >= 0 && i < len(s.Scores); i += dir {
+		if s.Scores[i].Replay != "" {
+			s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
+			s.Selected = i
+
+			if i < s.Offset {
+				s.Offset = i
+			} else if i >= s.Offset+s.Engine.Cfg.NScores {
+				s.Offset = i - s.Engine.Cfg.NScores + 1
+			}
+
+			// Stay on scores while selecting
+			s.Continue = false
+			return
+		}
+	}
+}
+
+// Returns index of score with replay at position, -1 if none
+func (s *Scores) ScoreAt(x, y float64) int {
+	if s.IsHighScore || !s.Loaded {
+		return -1
+	}
+
+	for i := s.Offset; i < s.Offset+s.Count(); i++ {
+		sc := s.Scores[i]
+		if sc.Replay != "" && x >= sc.X && x <= sc.X+sc.Width+150 && y >= sc.Y && y <= sc.Y+sc.Height {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Plays replay of score
+func (s *Scores) Play(i int) {
+	rep, err := LoadReplay(s.Scores[i].Replay)
+	if err != nil {
+		log.Error("LoadReplay: %s\n", err)
+		return
+	}
+
+	s.Resource.PlaySound(s.Resource.SoundClick, -1, 0)
+	s.Engine.State.Change(NewViewer(s.Engine, s.Resource, rep, s.Daily))
+}
+
+// Returns scores file name
+func (s *Scores) File() string {
+	if s.Daily {
+		return "daily"
+	}
+	return "scores"
+}
+
 // Loads scores from file
 func (s *Scores) Load() {
-	file := filepath.Join(home.Dir(), ".vov", "scores")
+	file := filepath.Join(home.Dir(), ".vov", s.File())
 	js, err := ioutil.ReadFile(file)
 	if err != nil {
 		log.Error("ReadFile: %s\n", err)
@@ -349,15 +557,40 @@ func (s *Scores) Save() {
 		log.Error("MarshalIndent: %s\n", err)
 	}
 
-	err = ioutil.WriteFile(filepath.Join(dir, "scores"), js, 0644)
+	err = ioutil.WriteFile(filepath.Join(dir, s.File()), js, 0644)
 	if err != nil {
 		log.Error("WriteFile: %s\n", err)
 	}
 }
 
+// Returns replay file of best score with replay, empty if there is none
+func BestReplay() string {
+	file := filepath.Join(home.Dir(), ".vov", "scores")
+	js, err := ioutil.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+
+	var scores []Score
+	err = json.Unmarshal(js, &scores)
+	if err != nil {
+		log.Error("Unmarshal: %s\n", err)
+		return ""
+	}
+
+	// Scores are sorted, best first
+	for _, s := range scores {
+		if s.Replay != "" {
+			return s.Replay
+		}
+	}
+
+	return ""
+}
+
 // Checks if scores file exists
 func (s *Scores) Exists() bool {
-	file := filepath.Join(home.Dir(), ".vov", "scores")
+	file := filepath.Join(home.Dir(), ".vov", s.File())
 	if _, err := os.Stat(file); err == nil {
 		return true
 	}
@@ -379,7 +612,7 @@ func (s *Scores) UpdateState() {
 // Updates scores
 func (s *Scores) Update() {
 	// Don't update if timer is paused
-	if engine.Paused {
+	if s.Engine.Clock.Paused {
 		return
 	}
 
@@ -391,9 +624,9 @@ func (s *Scores) Update() {
 	s.FadeTimer += s.Engine.TFrame / 2.0
 
 	// Update text
-	for i := 0; i < s.Engine.Cfg.NScores; i++ {
+	for i := s.Offset; i < s.Offset+s.Count(); i++ {
 		s.Scores[i].X = (s.Engine.Cfg.WinWidth-s.Scores[i].Width)/2 + math.Cos(s.FadeTimer/6.5)*10
-		s.Scores[i].Y = (s.Engine.Cfg.WinHeight/2 - (float64(s.Engine.Cfg.NScores) * float64(s.Scores[i].Height)) + float64(i*40)) + math.Sin(s.FadeTimer/5.0)*10
+		s.Scores[i].Y = (s.Engine.Cfg.WinHeight/2 - (float64(s.Engine.Cfg.NScores) * float64(s.Scores[i].Height)) + float64((i-s.Offset)*40)) + math.Sin(s.FadeTimer/5.0)*10
 	}
 
 	// Update dust
@@ -414,13 +647,22 @@ func (s *Scores) Draw() {
 	if !s.IsHighScore {
 		// Draw scores
 		if s.Loaded {
-			for i := 0; i < s.Engine.Cfg.NScores; i++ {
+			if s.Daily {
+				w, _, _ := s.Resource.FontMedium.SizeUTF8("DAILY")
+				s.Resource.DrawText("DAILY", int32(s.Engine.Cfg.WinWidth-float64(w))/2, 60, engine.FONT_MEDIUM)
+			}
+
+			for i := s.Offset; i < s.Offset+s.Count(); i++ {
 				x := int32(s.Scores[i].X)
 				y := int32(s.Scores[i].Y)
 
+				if i == s.Selected {
+					s.Resource.DrawText(">", x-25, y, engine.FONT_MEDIUM)
+				}
+
 				s.Resource.DrawText(fmt.Sprintf("%d.", i+1), x, y+1, engine.FONT_SMALL)
 				s.Resource.DrawText(s.Scores[i].Formatted, x+50, y, engine.FONT_MEDIUM)
-				s.Resource.DrawText(s.Scores[i].Name, x+150, y, engine.FONT_MEDIUM)
+				s.Resource.DrawText(s.Label(i), x+150, y, engine.FONT_MEDIUM)
 			}
 		} else {
 			// Draw loading screen
@@ -0,0 +1,58 @@
+// VoV game
+package game
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDailyHistory(t *testing.T) {
+	e, r := newTestEngine(t, 1)
+	n := e.Cfg.NScores
+
+	s := NewDailyScores(e, r, 0, "", "")
+	s.LoadBoard()
+
+	// More days than shown
+	for day := 1; day <= n+4; day++ {
+		s.Date = fmt.Sprintf("2020-01-%02d", day)
+		s.Current = day * 1000
+		s.CurrentReplay = ""
+		if day == 2 {
+			s.CurrentReplay = "old.vvr"
+		}
+		s.InsertDaily()
+	}
+	s.Save()
+
+	s = NewDailyScores(e, r, 0, "", "")
+	s.LoadBoard()
+
+	if len(s.Scores) != n+4 || s.Count() != n {
+		t.Fatalf("loaded %d days showing %d, want %d showing %d", len(s.Scores), s.Count(), n+4, n)
+	}
+
+	if s.Scores[0].Date != fmt.Sprintf("2020-01-%02d", n+4) {
+		t.Errorf("first day %s, want most recent", s.Scores[0].Date)
+	}
+
+	// Selecting older day scrolls to it
+	s.Select(1)
+
+	if s.Selected != n+2 || s.Offset != 3 || s.Count() != n {
+		t.Errorf("selected %d offset %d count %d, want %d 3 %d", s.Selected, s.Offset, s.Count(), n+2, n)
+	}
+
+	// Scrolling back hides selection
+	s.Scroll(-n)
+
+	if s.Offset != 0 || s.Selected != -1 {
+		t.Errorf("offset %d selected %d, want 0 -1", s.Offset, s.Selected)
+	}
+
+	s.Scroll(3 * n)
+
+	if s.Offset != 4 || s.Count() != n {
+		t.Errorf("offset %d count %d, want 4 %d", s.Offset, s.Count(), n)
+	}
+}
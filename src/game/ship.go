@@ -5,10 +5,8 @@ import (
 	"math"
 
 	"github.com/veandco/go-sdl2/sdl"
-	"github.com/veandco/go-sdl2/sdl_mixer"
 
 	"github.com/gen2brain/vov/src/engine"
-	"github.com/gen2brain/vov/src/system/rumble"
 )
 
 // Ship states
@@ -33,6 +31,12 @@ type Ship struct {
 	Moving      bool
 	Transparent bool
 
+	Alpha uint8
+
+	// Thrust vector, components in range -1..1
+	ThrustX float64
+	ThrustY float64
+
 	StateTimeout  float64
 	TranspTimeout float64
 
@@ -59,17 +63,46 @@ func NewShip(g *Game) *Ship {
 	return s
 }
 
+// Sets thrust vector and jets that fire
+func (s *Ship) SetThrust(x, y float64) {
+	s.ThrustX = x
+	s.ThrustY = y
+
+	s.Jets = 0
+	for dir := 0; dir < 4; dir++ {
+		if s.JetThrust(dir) > 0 {
+			s.Jets |= 1 << uint(dir)
+		}
+	}
+}
+
+// Returns thrust of jet in range 0..1, jets are left, down, right and up
+func (s *Ship) JetThrust(dir int) float64 {
+	switch dir {
+	case 0:
+		return math.Max(-s.ThrustX, 0)
+	case 1:
+		return math.Max(s.ThrustY, 0)
+	case 2:
+		return math.Max(s.ThrustX, 0)
+	case 3:
+		return math.Max(-s.ThrustY, 0)
+	}
+
+	return 0
+}
+
 // Initialize ship
 func (s *Ship) Init() {
 	s.Type = SHIP
 	s.Lives = 4
 	s.Flags = MOVE | DRAW | COLLIDE
 	s.State = PLAIN
+	s.Alpha = 255
 
 	s.Texture = s.Game.Resource.Ship
-	s.Query()
-
 	s.Surface = s.Game.Resource.ShipSurf
+	s.Query()
 
 	s.X = s.Cfg.WinWidth / 2.2
 	s.Y = s.Cfg.WinHeight/2 - s.Width/2
@@ -79,7 +112,9 @@ func (s *Ship) Init() {
 	s.Exp1 = NewSprite(s.Game.Engine, s.Game.Resource.Explosion1)
 
 	s.Glow = NewSprite(s.Game.Engine, s.Game.Resource.ShipGlow)
-	s.Glow.Texture.SetBlendMode(sdl.BLENDMODE_ADD)
+	if s.Glow.Texture != nil {
+		s.Glow.Texture.SetBlendMode(sdl.BLENDMODE_ADD)
+	}
 
 	s.LifePowText = NewSprite(s.Game.Engine, s.Game.Resource.LifePowText)
 	s.ShieldsPowText = NewSprite(s.Game.Engine, s.Game.Resource.ShieldsPowText)
@@ -95,27 +130,21 @@ func (s *Ship) Init() {
 func (s *Ship) Kill() {
 	s.ExpActive = true
 
-	// Play explosion sound
-	s.Game.Resource.PlaySound(s.Game.Resource.SoundExplosion1, 2, 0)
-
-	// Play rumble
-	if s.Cfg.HapticEnabled {
-		rumble.RumblePlay(1.0, 1000)
-	}
-
 	// Take life
 	s.Lives -= 1
 
+	s.Game.Events.Publish(Event{Type: ShipKilled, State: s.State})
+
 	s.FadeSound()
 	s.Game.Direction.Motion = false
 	s.Game.Direction.SetStates(false)
 	s.PowupTextActive = false
 
-	s.Cfg.GameSpeed = 1.0
-	s.Cfg.EngineDots = 1000
+	s.Cfg.Speed = s.Cfg.GameSpeed
+	s.Cfg.ShipDots = s.Cfg.EngineDots
 
 	if s.Lives == 0 {
-		s.Game.State = GameOver
+		s.Game.SetState(GameOver)
 		s.Game.StateTimeout = s.Cfg.GameOverLength
 
 		s.Flags = 0
@@ -124,7 +153,7 @@ func (s *Ship) Kill() {
 		s.DX = s.Cfg.BarrierSpeed
 		s.DY = 0
 	} else {
-		s.Game.State = DeadPause
+		s.Game.SetState(DeadPause)
 		s.Game.StateTimeout = s.Cfg.DeadPauseLength
 
 		// Want ship to be invisible, but keep drifting at sqrt(speed)
@@ -154,7 +183,7 @@ func (s *Ship) Kill() {
 // Plays engine sound
 func (s *Ship) PlaySound() {
 	s.Moving = true
-	if mix.Playing(1) == 0 {
+	if !s.Game.Resource.Playing(1) {
 		if s.State == ENGINEBLAST {
 			s.Game.Resource.PlaySound(s.Game.Resource.SoundEngine2, 1, 0)
 		} else {
@@ -166,8 +195,8 @@ func (s *Ship) PlaySound() {
 // Fades engine sound
 func (s *Ship) FadeSound() {
 	s.Moving = false
-	if mix.Playing(1) == 1 {
-		mix.FadeOutChannel(1, 100)
+	if s.Game.Resource.Playing(1) {
+		s.Game.Resource.FadeOutChannel(1, 100)
 	}
 }
 
@@ -179,31 +208,26 @@ func (s *Ship) Collisions() {
 			if s.Collide(s.Game.Powups.Powups[i]) {
 				s.Game.Powups.Powups[i].Active = false
 
-				// Restore default config
+				// Restore runtime values
 				if s.State == SLOWDOWN && s.Game.Powups.Powups[i].State != PLAIN {
-					s.Game.Engine.Cfg.GameSpeed = 1.0
+					s.Cfg.Speed = s.Cfg.GameSpeed
 				}
 				if s.State == ENGINEBLAST && s.Game.Powups.Powups[i].State != PLAIN {
-					s.Game.Engine.Cfg.EngineDots = 1000
+					s.Cfg.ShipDots = s.Cfg.EngineDots
 				}
 
 				switch s.Game.Powups.Powups[i].State {
-				case PLAIN:
-					s.Game.Resource.PlaySound(s.Game.Resource.SoundPowup0, -1, 0)
-				case INVINCIBLE:
-					s.Game.Resource.PlaySound(s.Game.Resource.SoundPowup1, -1, 0)
 				case ENGINEBLAST:
-					s.Game.Resource.PlaySound(s.Game.Resource.SoundPowup2, -1, 0)
-					s.Game.Engine.Cfg.EngineDots = 1500
-				case SHIELDS:
-					s.Game.Resource.PlaySound(s.Game.Resource.SoundPowup3, -1, 0)
-				case ATTACK:
-					s.Game.Resource.PlaySound(s.Game.Resource.SoundPowup4, -1, 0)
+					s.Cfg.ShipDots = s.Cfg.MaxShipDots
+					if blast := s.Cfg.EngineDots * 3 / 2; blast < s.Cfg.MaxShipDots {
+						s.Cfg.ShipDots = blast
+					}
 				case SLOWDOWN:
-					s.Game.Resource.PlaySound(s.Game.Resource.SoundPowup5, -1, 0)
-					s.Game.Engine.Cfg.GameSpeed = 0.50
+					s.Cfg.Speed = s.Cfg.GameSpeed / 2
 				}
 
+				s.Game.Events.Publish(Event{Type: PowupCollected, State: s.Game.Powups.Powups[i].State})
+
 				s.PowupTextActive = true
 				s.PowupTextScale = s.Game.Engine.Cfg.PowupTextScale
 				s.PowupTextTimeout = s.Game.Engine.Cfg.PowupTextTimeout
@@ -229,70 +253,47 @@ func (s *Ship) Collisions() {
 		if s.Game.Rocks.Rocks[i] != nil && s.Game.Rocks.Rocks[i].Active {
 
 			if s.Collide(s.Game.Rocks.Rocks[i]) {
+				s.Game.Events.Publish(Event{Type: RockHit, State: s.State, Rock: s.Game.Rocks.Rocks[i]})
+
 				switch s.State {
 				case PLAIN, SLOWDOWN:
-					if mix.Playing(2) == 0 {
-						s.Game.Resource.PlaySound(s.Game.Resource.SoundExplosion2, 2, 0)
-					} else {
-						mix.FadeOutChannel(2, 10)
-						s.Game.Resource.PlaySound(s.Game.Resource.SoundExplosion2, 2, 0)
-					}
-
 					// Kill ship
 					s.Kill()
 
 					// Kill rock
 					s.Game.Rocks.Rocks[i].Kill()
+					s.Game.Events.Publish(Event{Type: RockDestroyed, State: s.State, Rock: s.Game.Rocks.Rocks[i], ByShip: true})
 
 				case SHIELDS:
-					if mix.Playing(2) == 0 {
-						s.Game.Resource.PlaySound(s.Game.Resource.SoundBounce, 2, 0)
-					} else {
-						mix.FadeOutChannel(2, 10)
-						s.Game.Resource.PlaySound(s.Game.Resource.SoundBounce, 2, 0)
-					}
-
 					// Bounce ship
 					s.Bounce(s.Game.Rocks.Rocks[i])
+					s.Game.Events.Publish(Event{Type: ShipBounced, State: s.State, Rock: s.Game.Rocks.Rocks[i]})
 
 				case ATTACK:
-					if mix.Playing(2) == 0 {
-						s.Game.Resource.PlaySound(s.Game.Resource.SoundExplosion2, 2, 0)
-					} else {
-						mix.FadeOutChannel(2, 10)
-						s.Game.Resource.PlaySound(s.Game.Resource.SoundExplosion2, 2, 0)
-					}
-
 					// Bounce ship
 					s.Bounce(s.Game.Rocks.Rocks[i])
+					s.Game.Events.Publish(Event{Type: ShipBounced, State: s.State, Rock: s.Game.Rocks.Rocks[i]})
 
 					// Kill rock
 					s.Game.Rocks.Rocks[i].Kill()
+					s.Game.Events.Publish(Event{Type: RockDestroyed, State: s.State, Rock: s.Game.Rocks.Rocks[i], ByShip: true})
 
 					// New bang dots
 					s.Game.Dots.NewBangDots(s.Game.Rocks.Rocks[i])
 
 				case INVINCIBLE:
 					// Set alpha transparency
-					s.Texture.SetAlphaMod(100)
+					s.Alpha = 100
 					s.Transparent = true
 					s.TranspTimeout = 200
 
-					s.Game.Resource.PlaySoundTimed(s.Game.Resource.SoundEngine3, 2, 0, 200)
-
 				case ENGINEBLAST:
-					if mix.Playing(2) == 0 {
-						s.Game.Resource.PlaySound(s.Game.Resource.SoundExplosion2, 2, 0)
-					} else {
-						mix.FadeOutChannel(2, 10)
-						s.Game.Resource.PlaySound(s.Game.Resource.SoundExplosion2, 2, 0)
-					}
-
 					// Kill ship
 					s.Kill()
 
 					// Kill rock
 					s.Game.Rocks.Rocks[i].Kill()
+					s.Game.Events.Publish(Event{Type: RockDestroyed, State: s.State, Rock: s.Game.Rocks.Rocks[i], ByShip: true})
 				}
 			}
 		}
@@ -302,17 +303,17 @@ func (s *Ship) Collisions() {
 // Updates ship state
 func (s *Ship) UpdateState() {
 	if s.StateTimeout > 0 {
-		s.StateTimeout -= float64(s.Game.Engine.FrameDelta)
+		s.StateTimeout -= float64(s.Game.Engine.StepDelta)
 		return
 	}
 
 	if s.State != PLAIN {
-		// Restore default config
+		// Restore runtime values
 		if s.State == SLOWDOWN {
-			s.Game.Engine.Cfg.GameSpeed = 1.0
+			s.Cfg.Speed = s.Cfg.GameSpeed
 		}
 		if s.State == ENGINEBLAST {
-			s.Game.Engine.Cfg.EngineDots = 1000
+			s.Cfg.ShipDots = s.Cfg.EngineDots
 		}
 
 		// Restore default state
@@ -323,14 +324,39 @@ func (s *Ship) UpdateState() {
 // Updates ship transparency
 func (s *Ship) UpdateTransp() {
 	if s.TranspTimeout > 0 {
-		s.TranspTimeout -= float64(s.Game.Engine.FrameDelta)
+		s.TranspTimeout -= float64(s.Game.Engine.StepDelta)
 		return
 	}
 
 	if s.Transparent {
-		s.Texture.SetAlphaMod(255)
+		s.Alpha = 255
 		s.Transparent = false
 	}
+
+	// Blink if state is to expire
+	if s.StateTimeout > 0 && s.StateTimeout < 1000 && !s.Transparent {
+		// Set alpha transparency
+		s.Alpha = 80
+		s.Transparent = true
+		s.TranspTimeout = 100
+	}
+}
+
+// Updates powup text
+func (s *Ship) UpdatePowupText() {
+	if !s.PowupTextActive || s.Game.State != GamePlay {
+		return
+	}
+
+	if s.PowupTextScale <= 1.0 {
+		s.PowupTextTimeout -= float64(s.Game.Engine.StepDelta)
+		if s.PowupTextTimeout <= 0 {
+			s.PowupTextActive = false
+		}
+	} else {
+		// Shrink by 0.03 every 1/60th of a second
+		s.PowupTextScale -= 1.8 * float64(s.Game.Engine.StepDelta) / 1000
+	}
 }
 
 // Updates ship
@@ -341,6 +367,12 @@ func (s *Ship) Update() {
 	// Update transparency
 	s.UpdateTransp()
 
+	// Update powup text
+	s.UpdatePowupText()
+
+	// Update explosion
+	s.UpdateExplosion()
+
 	// Scrolling
 	tmp := (s.Y+s.Height/2+s.DY*s.Game.Engine.TFrame-s.Cfg.YScrollTo)/25 + (s.DY - s.Game.Engine.ScreenDY)
 	s.Game.Engine.ScreenDY += tmp * s.Game.Engine.TFrame / 12
@@ -348,8 +380,8 @@ func (s *Ship) Update() {
 	s.Game.Engine.ScreenDX += tmp * s.Game.Engine.TFrame / 12
 
 	// Taper off so we don't hit the barrier abruptly. If we would hit in < 2 seconds, adjust to 2 seconds
-	if s.Cfg.DistAhead+(s.Game.Engine.ScreenDX-s.Cfg.BarrierSpeed)*toTicks(2, s.Cfg.GameSpeed) < 0 {
-		s.Game.Engine.ScreenDX = s.Cfg.BarrierSpeed - (s.Cfg.DistAhead / toTicks(2, s.Cfg.GameSpeed))
+	if s.Cfg.DistAhead+(s.Game.Engine.ScreenDX-s.Cfg.BarrierSpeed)*toTicks(2, s.Cfg.Speed) < 0 {
+		s.Game.Engine.ScreenDX = s.Cfg.BarrierSpeed - (s.Cfg.DistAhead / toTicks(2, s.Cfg.Speed))
 	}
 	s.Cfg.DistAhead += (s.Game.Engine.ScreenDX - s.Cfg.BarrierSpeed) * s.Game.Engine.TFrame
 	if s.Cfg.MaxDistAhead >= 0 {
@@ -405,25 +437,22 @@ func (s *Ship) DrawPowupText() {
 
 		dest := &sdl.Rect{int32(x), int32(y), int32(width), int32(height)}
 
-		s.Game.Engine.Renderer.Copy(spr.Texture, src, dest)
-
-		if s.PowupTextScale <= 1.0 {
-			s.PowupTextTimeout -= float64(s.Game.Engine.FrameDelta)
-			if s.PowupTextTimeout <= 255 {
-				spr.Texture.SetAlphaMod(uint8(s.PowupTextTimeout))
-			}
-			if s.PowupTextTimeout <= 0 {
-				s.PowupTextActive = false
-				spr.Texture.SetAlphaMod(255)
-			}
+		// Fade out text
+		if s.PowupTextScale <= 1.0 && s.PowupTextTimeout <= 255 {
+			spr.Texture.SetAlphaMod(uint8(math.Max(s.PowupTextTimeout, 0)))
 		} else {
-			s.PowupTextScale -= 0.03
+			spr.Texture.SetAlphaMod(255)
 		}
+
+		s.Game.Engine.Renderer.Copy(spr.Texture, src, dest)
 	}
 }
 
 // Draws ship
 func (s *Ship) Draw() {
+	// Set alpha transparency
+	s.Texture.SetAlphaMod(s.Alpha)
+
 	s.Sprite.Draw()
 
 	// Draw glow
@@ -433,20 +462,9 @@ func (s *Ship) Draw() {
 		s.Glow.Draw()
 	}
 
-	// Blink if state is to expire
-	if s.StateTimeout > 0 && s.StateTimeout < 1000 && !s.Transparent {
-		// Set alpha transparency
-		s.Texture.SetAlphaMod(80)
-		s.Transparent = true
-		s.TranspTimeout = 100
-	}
-
 	// Draw powup text
 	s.DrawPowupText()
 
 	// Draw explosion
 	s.Explode()
-
-	// Reset jets
-	s.Jets = 0
 }
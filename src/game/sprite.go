@@ -71,8 +71,30 @@ func NewSprite(e *engine.Engine, texture *sdl.Texture) (s *Sprite) {
 	return
 }
 
+// Returns new sprite with collision surface
+func NewSurfaceSprite(e *engine.Engine, texture *sdl.Texture, surface *sdl.Surface) (s *Sprite) {
+	s = &Sprite{}
+	s.Engine = e
+
+	s.Flags = DRAW
+	s.Texture = texture
+	s.Surface = surface
+	s.Query()
+
+	return
+}
+
 // Queries sprite texture dimensions
 func (s *Sprite) Query() {
+	if s.Texture == nil {
+		// Headless, use surface dimensions
+		if s.Surface != nil {
+			s.Width = float64(s.Surface.W)
+			s.Height = float64(s.Surface.H)
+		}
+		return
+	}
+
 	_, _, w, h, err := s.Texture.Query()
 	if err != nil {
 		log.Error("Query: %s\n", err)
@@ -205,8 +227,8 @@ func (s *Sprite) Collide(b *Sprite) bool {
 	return false
 }
 
-// Explodes sprite
-func (s *Sprite) Explode() {
+// Updates explosion frame
+func (s *Sprite) UpdateExplosion() {
 	if !s.ExpActive || s.Exp1 == nil {
 		return
 	}
@@ -220,31 +242,36 @@ func (s *Sprite) Explode() {
 		s.ExpFrame = 0
 		s.ExpActive = false
 	} else {
-		if s.Type == ROCK {
-			if s.Width >= 48 {
-				// Large explosion
-				width := int32(s.Exp1.Width / float64(s.Engine.Cfg.NFrames))
-				src := &sdl.Rect{width * int32(s.ExpFrame), 0, int32(width), int32(s.Exp1.Height)}
-				dest := &sdl.Rect{int32(s.X) + int32(s.Width)/2 - width/2, int32(s.Y) + int32(s.Height)/2 - width/2, int32(width), int32(s.Exp1.Height)}
-				s.Engine.Renderer.Copy(s.Exp1.Texture, src, dest)
-			} else {
-				// Small explosion
-				width := int32(s.Exp2.Width / float64(s.Engine.Cfg.NFrames))
-				src := &sdl.Rect{width * int32(s.ExpFrame), 0, int32(width), int32(s.Exp2.Height)}
-				dest := &sdl.Rect{int32(s.X) + int32(s.Width)/2 - width/2, int32(s.Y) + int32(s.Height)/2 - width/2, int32(width), int32(s.Exp2.Height)}
-				s.Engine.Renderer.Copy(s.Exp2.Texture, src, dest)
-			}
-		} else {
+		// Increment explosion frame
+		s.ExpFrame++
+	}
+}
+
+// Explodes sprite
+func (s *Sprite) Explode() {
+	if !s.ExpActive || s.Exp1 == nil {
+		return
+	}
+
+	if s.Type == ROCK {
+		if s.Width >= 48 {
+			// Large explosion
 			width := int32(s.Exp1.Width / float64(s.Engine.Cfg.NFrames))
 			src := &sdl.Rect{width * int32(s.ExpFrame), 0, int32(width), int32(s.Exp1.Height)}
 			dest := &sdl.Rect{int32(s.X) + int32(s.Width)/2 - width/2, int32(s.Y) + int32(s.Height)/2 - width/2, int32(width), int32(s.Exp1.Height)}
 			s.Engine.Renderer.Copy(s.Exp1.Texture, src, dest)
+		} else {
+			// Small explosion
+			width := int32(s.Exp2.Width / float64(s.Engine.Cfg.NFrames))
+			src := &sdl.Rect{width * int32(s.ExpFrame), 0, int32(width), int32(s.Exp2.Height)}
+			dest := &sdl.Rect{int32(s.X) + int32(s.Width)/2 - width/2, int32(s.Y) + int32(s.Height)/2 - width/2, int32(width), int32(s.Exp2.Height)}
+			s.Engine.Renderer.Copy(s.Exp2.Texture, src, dest)
 		}
-	}
-
-	if !engine.Paused {
-		// Increment explosion frame
-		s.ExpFrame++
+	} else {
+		width := int32(s.Exp1.Width / float64(s.Engine.Cfg.NFrames))
+		src := &sdl.Rect{width * int32(s.ExpFrame), 0, int32(width), int32(s.Exp1.Height)}
+		dest := &sdl.Rect{int32(s.X) + int32(s.Width)/2 - width/2, int32(s.Y) + int32(s.Height)/2 - width/2, int32(width), int32(s.Exp1.Height)}
+		s.Engine.Renderer.Copy(s.Exp1.Texture, src, dest)
 	}
 }
 
@@ -300,8 +327,6 @@ func (s *Sprite) Draw() {
 	}
 
 	if s.Type == ROCK {
-		s.Frame = (s.Engine.StartTicks / 50) % s.Engine.Cfg.NFrames
-
 		dest := s.Rect()
 		src := &sdl.Rect{int32(uint32(s.Width) * s.Frame), 0, int32(s.Width), int32(s.Height)}
 
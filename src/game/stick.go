@@ -0,0 +1,91 @@
+// VoV game
+package game
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Stick structure, virtual joystick anchored where finger lands
+type Stick struct {
+	Game *Game
+
+	Active bool
+
+	// Base center
+	X float64
+	Y float64
+
+	// Knob center
+	KnobX float64
+	KnobY float64
+
+	// Base radius, knob is clamped to it
+	Radius float64
+
+	Points []sdl.Point
+}
+
+// Returns new virtual stick
+func NewStick(g *Game) (s *Stick) {
+	s = &Stick{}
+	s.Game = g
+	s.Radius = g.Cfg.DragRadius * g.Cfg.WinHeight
+	s.Points = make([]sdl.Point, 0)
+
+	return
+}
+
+// Shows stick at position
+func (s *Stick) Start(x, y float64) {
+	s.Active = true
+	s.X, s.Y = x, y
+	s.KnobX, s.KnobY = x, y
+}
+
+// Moves knob towards position
+func (s *Stick) Move(x, y float64) {
+	dx, dy := x-s.X, y-s.Y
+
+	if d := math.Hypot(dx, dy); d > s.Radius {
+		dx, dy = dx/d*s.Radius, dy/d*s.Radius
+	}
+
+	s.KnobX, s.KnobY = s.X+dx, s.Y+dy
+}
+
+// Hides stick
+func (s *Stick) Stop() {
+	s.Active = false
+}
+
+// Draws stick
+func (s *Stick) Draw() {
+	if !s.Active {
+		return
+	}
+
+	s.Points = s.Points[:0]
+
+	// Base ring
+	n := int(s.Radius)
+	for i := 0; i < n; i++ {
+		a := float64(i) / float64(n) * math.Pi * 2
+		s.Points = append(s.Points, sdl.Point{int32(s.X + math.Cos(a)*s.Radius), int32(s.Y + math.Sin(a)*s.Radius)})
+	}
+
+	// Knob
+	r := s.Radius / 3
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				s.Points = append(s.Points, sdl.Point{int32(s.KnobX + x), int32(s.KnobY + y)})
+			}
+		}
+	}
+
+	s.Game.Engine.Renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	s.Game.Engine.Renderer.SetDrawColor(255, 255, 255, 80)
+	s.Game.Engine.Renderer.DrawPoints(s.Points)
+}
@@ -0,0 +1,91 @@
+// VoV game
+package game
+
+import (
+	"math"
+)
+
+// Tilt structure, turns accelerometer axes into thrust vector
+type Tilt struct {
+	// Last axis values
+	Raw [2]float64
+
+	// Axis values of neutral orientation
+	Neutral [2]float64
+
+	// Smoothed axis values, relative to neutral
+	Filtered [2]float64
+
+	// Waiting for axes to capture neutral orientation
+	Calibrating bool
+	Calibrated  [2]bool
+
+	// Axis values were received since calibration
+	Active bool
+}
+
+// Returns new tilt
+func NewTilt() (t *Tilt) {
+	t = &Tilt{}
+	t.Calibrate()
+	return
+}
+
+// Captures neutral orientation from next values of both axes
+func (t *Tilt) Calibrate() {
+	t.Calibrating = true
+	t.Calibrated = [2]bool{}
+	t.Filtered = [2]float64{}
+	t.Active = false
+}
+
+// Sets axis value, only first two axes are used
+func (t *Tilt) Axis(axis int, value int16) {
+	if axis < 0 || axis > 1 {
+		return
+	}
+
+	t.Raw[axis] = float64(value)
+
+	if t.Calibrating {
+		t.Neutral[axis] = t.Raw[axis]
+		t.Calibrated[axis] = true
+
+		if t.Calibrated[0] && t.Calibrated[1] {
+			t.Calibrating = false
+		}
+
+		return
+	}
+
+	t.Active = true
+}
+
+// Filters axis values, smoothing is in range 0..1, 0 is no smoothing
+func (t *Tilt) Update(smoothing float64) {
+	if t.Calibrating {
+		return
+	}
+
+	a := 1 - math.Max(math.Min(smoothing, 1), 0)
+	for i := 0; i < 2; i++ {
+		t.Filtered[i] += (t.Raw[i] - t.Neutral[i] - t.Filtered[i]) * a
+	}
+}
+
+// Returns thrust vector, full thrust is reached at 1/sensitivity of full tilt, deadzone is in axis units
+func (t *Tilt) Vector(sensitivity, deadzone float64) (x, y float64) {
+	x, y = t.Filtered[0], t.Filtered[1]
+
+	m := math.Hypot(x, y)
+	if m <= deadzone {
+		return 0, 0
+	}
+
+	r := (m - deadzone) / 32767 * sensitivity
+	if r > 1 {
+		r = 1
+	}
+
+	return x / m * r, y / m * r
+}
@@ -0,0 +1,121 @@
+// VoV game
+package game
+
+import (
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestTiltFilter(t *testing.T) {
+	tilt := NewTilt()
+
+	// First values of both axes are neutral orientation
+	tilt.Axis(0, 1000)
+	if !tilt.Calibrating {
+		t.Fatal("calibrated with one axis")
+	}
+
+	tilt.Axis(1, -2000)
+	if tilt.Calibrating || tilt.Active {
+		t.Fatal("not calibrated after both axes")
+	}
+
+	tilt.Axis(0, 1000+16000)
+	if !tilt.Active {
+		t.Fatal("not active after axis value")
+	}
+
+	// Smoothed value approaches tilt relative to neutral
+	tilt.Update(0.5)
+	if tilt.Filtered[0] != 8000 || tilt.Filtered[1] != 0 {
+		t.Errorf("filtered %v, want [8000 0]", tilt.Filtered)
+	}
+
+	for i := 0; i < 20; i++ {
+		tilt.Update(0.5)
+	}
+
+	if tilt.Filtered[0] < 15999 || tilt.Filtered[0] > 16000 {
+		t.Errorf("filtered %v, want about 16000", tilt.Filtered[0])
+	}
+
+	// No smoothing follows value
+	tilt.Axis(1, -2000-4000)
+	tilt.Update(0)
+	if tilt.Filtered[1] != -4000 {
+		t.Errorf("filtered %v, want -4000", tilt.Filtered[1])
+	}
+}
+
+func TestTiltVector(t *testing.T) {
+	tilt := &Tilt{}
+
+	tests := []struct {
+		x, y        float64
+		sensitivity float64
+		deadzone    float64
+		wantX       float64
+		wantY       float64
+	}{
+		{100, 0, 4, 500, 0, 0},
+		{0, 0, 4, 0, 0, 0},
+		{32767, 0, 1, 0, 1, 0},
+		{0, -32767 / 4, 4, 0, 0, -1},
+		{32767, 0, 4, 0, 1, 0},
+		{32767/2 + 500, 0, 1, 500, 0.5, 0},
+	}
+
+	for _, tt := range tests {
+		tilt.Filtered = [2]float64{tt.x, tt.y}
+
+		x, y := tilt.Vector(tt.sensitivity, tt.deadzone)
+		if !near(x, tt.wantX) || !near(y, tt.wantY) {
+			t.Errorf("Vector(%g, %g) of %g,%g = %g,%g, want %g,%g", tt.sensitivity, tt.deadzone, tt.x, tt.y, x, y, tt.wantX, tt.wantY)
+		}
+	}
+}
+
+func TestTiltSteering(t *testing.T) {
+	e, r := newTestEngine(t, 1)
+
+	e.Cfg.AccelerometerEnabled = true
+	e.Accelerometer = true
+	e.AccelerometerID = 3
+
+	g := NewGame(e, r)
+	g.SetSeed(1)
+	g.OnInit()
+	defer g.OnQuit()
+
+	axis := func(which sdl.JoystickID, axis uint8, value int16) {
+		g.HandleEvent(&sdl.JoyAxisEvent{Type: sdl.JOYAXISMOTION, Which: which, Axis: axis, Value: value})
+	}
+
+	// Neutral orientation, then device tilted right
+	axis(3, 0, 0)
+	axis(3, 1, 0)
+	axis(3, 0, 20000)
+
+	// Other joysticks don't steer
+	axis(0, 1, -20000)
+
+	for i := 0; i < 10; i++ {
+		e.Advance(uint32(e.Cfg.StepLength))
+		g.Update()
+	}
+
+	// Jets are reset after each update, direction keeps thrust
+	if x, y := g.Direction.Vector(); x < 0.9 || !near(y, 0) {
+		t.Errorf("thrust %g,%g, want right", x, y)
+	}
+
+	if g.Ship.DX <= e.Cfg.BarrierSpeed {
+		t.Errorf("ship speed %g, want faster than barrier %g", g.Ship.DX, e.Cfg.BarrierSpeed)
+	}
+}
+
+// Checks if values are nearly equal
+func near(a, b float64) bool {
+	return a-b < 0.01 && b-a < 0.01
+}
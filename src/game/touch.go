@@ -0,0 +1,87 @@
+// VoV game
+package game
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Touch structure, one finger on screen
+type Touch struct {
+	ID sdl.FingerID
+
+	// Touch down position, normalized
+	X float32
+	Y float32
+
+	// Touch down time
+	Timestamp uint32
+
+	// Finger moved beyond touch threshold
+	Moved bool
+
+	// Finger stayed down through pause, steering resumes from next motion
+	Paused bool
+}
+
+// Touches structure, tracks fingers by id
+type Touches struct {
+	Fingers map[sdl.FingerID]*Touch
+
+	// Finger that steers ship, nil if none
+	Steering *Touch
+}
+
+// Returns new touches
+func NewTouches() (t *Touches) {
+	t = &Touches{}
+	t.Fingers = make(map[sdl.FingerID]*Touch)
+	return
+}
+
+// Adds finger
+func (t *Touches) Down(ev *sdl.TouchFingerEvent) (touch *Touch) {
+	touch = &Touch{ev.FingerID, ev.X, ev.Y, ev.Timestamp, false, false}
+	t.Fingers[ev.FingerID] = touch
+	return
+}
+
+// Updates finger, returns nil if finger is not tracked
+func (t *Touches) Motion(ev *sdl.TouchFingerEvent, threshold float64) (touch *Touch) {
+	touch = t.Fingers[ev.FingerID]
+	if touch == nil {
+		return
+	}
+
+	if math.Hypot(float64(ev.X-touch.X), float64(ev.Y-touch.Y)) > threshold {
+		touch.Moved = true
+	}
+
+	return
+}
+
+// Removes finger, returns nil if finger is not tracked
+func (t *Touches) Up(ev *sdl.TouchFingerEvent) (touch *Touch) {
+	touch = t.Fingers[ev.FingerID]
+	delete(t.Fingers, ev.FingerID)
+
+	if touch != nil && touch == t.Steering {
+		t.Steering = nil
+	}
+
+	return
+}
+
+// Marks fingers that are down as paused
+func (t *Touches) Pause() {
+	for _, touch := range t.Fingers {
+		touch.Paused = true
+	}
+}
+
+// Forgets all fingers
+func (t *Touches) Reset() {
+	t.Fingers = make(map[sdl.FingerID]*Touch)
+	t.Steering = nil
+}
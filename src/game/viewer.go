@@ -0,0 +1,309 @@
+// VoV game
+package game
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/gen2brain/vov/src/engine"
+)
+
+// Viewer structure, plays recorded replay
+type Viewer struct {
+	Engine   *engine.Engine
+	Resource *engine.Resource
+
+	// Replay being played
+	Replay *Replay
+
+	// Replay was opened from daily leaderboard
+	Daily bool
+
+	// Game driven by replay
+	Game *Game
+
+	// Fast forward speed
+	Speed int
+
+	// Playback paused
+	Paused bool
+
+	// Step one tick while paused
+	StepTick bool
+
+	// Progress bar
+	Bar *sdl.Rect
+}
+
+// Returns new viewer
+func NewViewer(e *engine.Engine, r *engine.Resource, rep *Replay, daily bool) (v *Viewer) {
+	v = &Viewer{}
+	v.Engine = e
+	v.Resource = r
+	v.Replay = rep
+	v.Daily = daily
+
+	return
+}
+
+// Initializes state
+func (v *Viewer) OnInit() bool {
+	v.Speed = 1
+	v.Paused = false
+
+	v.Game = NewReplayGame(v.Engine, v.Resource, v.Replay)
+	v.Game.OnInit()
+
+	v.Bar = &sdl.Rect{20, int32(v.Engine.Cfg.WinHeight) - 30, int32(v.Engine.Cfg.WinWidth) - 40, 8}
+
+	return true
+}
+
+// Quits state
+func (v *Viewer) OnQuit() bool {
+	return v.Game.OnQuit()
+}
+
+// Returns state string
+func (v *Viewer) String() string {
+	return "Viewer"
+}
+
+// Handles input events
+func (v *Viewer) HandleEvents() {
+	if v.Engine.Clock.Paused {
+		event := sdl.WaitEvent()
+		if event != nil {
+			v.HandleEvent(event)
+		}
+	} else {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			v.HandleEvent(event)
+		}
+	}
+}
+
+// Handles input event
+func (v *Viewer) HandleEvent(event sdl.Event) {
+	// Handle mapped actions, other keys control playback
+	if a, pressed := v.Engine.Input.Actions(event); pressed {
+		switch {
+		case a&engine.ACTION_BACK != 0:
+			v.Back()
+			return
+		case a&(engine.ACTION_PAUSE|engine.ACTION_CONFIRM) != 0:
+			v.TogglePause()
+			return
+		case a&engine.ACTION_TOGGLE_FULLSCREEN != 0:
+			v.Engine.Fullscreen()
+			return
+		}
+	}
+
+	switch t := event.(type) {
+	case *sdl.QuitEvent:
+		// Handle quit event
+		v.Engine.Quit()
+
+	case *sdl.KeyDownEvent:
+		switch t.Keysym.Scancode {
+		case sdl.SCANCODE_BACKSPACE:
+			// Change state on backspace
+			v.Back()
+		case sdl.SCANCODE_PERIOD:
+			v.StepFrame()
+		case sdl.SCANCODE_1:
+			v.Speed = 1
+		case sdl.SCANCODE_2:
+			v.Speed = 2
+		case sdl.SCANCODE_4:
+			v.Speed = 4
+		case sdl.SCANCODE_TAB:
+			v.CycleSpeed()
+		case sdl.SCANCODE_LEFT:
+			v.SeekBy(-5000)
+		case sdl.SCANCODE_RIGHT:
+			v.SeekBy(5000)
+		case sdl.SCANCODE_PAGEUP:
+			v.SeekBy(-30000)
+		case sdl.SCANCODE_PAGEDOWN:
+			v.SeekBy(30000)
+		case sdl.SCANCODE_HOME:
+			v.Seek(0)
+		case sdl.SCANCODE_END:
+			v.Seek(v.Replay.Header.Ticks)
+		}
+
+	case *sdl.MouseButtonEvent:
+		if t.Type == sdl.MOUSEBUTTONDOWN && t.Button == sdl.BUTTON_LEFT {
+			v.Click(t.X, t.Y)
+		}
+
+	case *sdl.TouchFingerEvent:
+		if t.Type == sdl.FINGERDOWN {
+			v.Click(int32(t.X*float32(v.Engine.Cfg.WinWidth)), int32(t.Y*float32(v.Engine.Cfg.WinHeight)))
+		}
+
+	case *sdl.ControllerDeviceEvent:
+		// Initialize/Remove controller
+		if t.Type == sdl.CONTROLLERDEVICEADDED {
+			v.Engine.Controller = sdl.GameControllerOpen(int(t.Which))
+			if v.Engine.Cfg.HapticEnabled {
+				v.Engine.SetHaptic()
+			}
+		} else if t.Type == sdl.CONTROLLERDEVICEREMOVED {
+			v.Engine.CloseController()
+		}
+
+	case *sdl.ControllerButtonEvent:
+		// Controller buttons
+		if t.Type == sdl.CONTROLLERBUTTONDOWN {
+			switch t.Button {
+			case sdl.CONTROLLER_BUTTON_LEFTSHOULDER:
+				v.StepFrame()
+			case sdl.CONTROLLER_BUTTON_RIGHTSHOULDER:
+				v.CycleSpeed()
+			case sdl.CONTROLLER_BUTTON_DPAD_LEFT:
+				v.SeekBy(-5000)
+			case sdl.CONTROLLER_BUTTON_DPAD_RIGHT:
+				v.SeekBy(5000)
+			}
+		}
+
+	default:
+		break
+	}
+}
+
+// Changes state back to leaderboard replay was opened from
+func (v *Viewer) Back() {
+	v.Resource.PlaySound(v.Resource.SoundClick, -1, 0)
+
+	s := NewScores(v.Engine, v.Resource, 0, "", false)
+	s.Daily = v.Daily
+	v.Engine.State.Change(s)
+}
+
+// Toggles playback pause
+func (v *Viewer) TogglePause() {
+	v.Resource.PlaySound(v.Resource.SoundClick, -1, 0)
+	v.Paused = !v.Paused
+}
+
+// Pauses playback and steps one tick
+func (v *Viewer) StepFrame() {
+	v.Paused = true
+	v.StepTick = true
+}
+
+// Cycles fast forward speed
+func (v *Viewer) CycleSpeed() {
+	switch v.Speed {
+	case 1:
+		v.Speed = 2
+	case 2:
+		v.Speed = 4
+	default:
+		v.Speed = 1
+	}
+}
+
+// Seeks or toggles pause on click
+func (v *Viewer) Click(x, y int32) {
+	// Progress bar with some space around
+	if x >= v.Bar.X && x <= v.Bar.X+v.Bar.W && y >= v.Bar.Y-10 && y <= v.Bar.Y+v.Bar.H+10 {
+		// Seek to position on progress bar
+		v.Seek(int(float64(x-v.Bar.X) / float64(v.Bar.W) * float64(v.Replay.Header.Ticks)))
+	} else {
+		v.TogglePause()
+	}
+}
+
+// Seeks by milliseconds
+func (v *Viewer) SeekBy(ms int) {
+	v.Seek(v.Game.Tick + ms/v.Replay.Header.Config.StepLength)
+}
+
+// Seeks to tick, seeking backwards restarts replay from beginning
+func (v *Viewer) Seek(tick int) {
+	if tick < 0 {
+		tick = 0
+	} else if tick > v.Replay.Header.Ticks {
+		tick = v.Replay.Header.Ticks
+	}
+
+	if tick < v.Game.Tick {
+		// Rebuild simulation only, music keeps playing and config saved before replay is kept
+		g := NewReplayGame(v.Engine, v.Resource, v.Replay)
+		g.SavedConfig = v.Game.SavedConfig
+		g.Init()
+
+		v.Game = g
+	}
+
+	// Simulate without sounds
+	sounds := v.Engine.Cfg.SoundsEnabled
+	v.Engine.Cfg.SoundsEnabled = false
+
+	step := uint32(v.Replay.Header.Config.StepLength)
+	for v.Game.Tick < tick && v.Game.State != GameQuit {
+		v.Engine.Simulate(step)
+		v.Game.Update()
+	}
+
+	v.Engine.Cfg.SoundsEnabled = sounds
+}
+
+// Updates viewer
+func (v *Viewer) Update() {
+	// Don't update if timer is paused
+	if v.Engine.Clock.Paused {
+		return
+	}
+
+	if v.Paused {
+		if v.StepTick {
+			v.StepTick = false
+			v.Game.Update()
+		}
+		return
+	}
+
+	for i := 0; i < v.Speed; i++ {
+		v.Game.Update()
+	}
+
+	// Pause at the end of replay
+	if v.Game.State == GameQuit {
+		v.Paused = true
+	}
+}
+
+// Draws viewer
+func (v *Viewer) Draw() {
+	// Draw game
+	v.Game.Draw()
+
+	// Draw progress bar
+	v.Engine.Renderer.SetDrawColor(60, 60, 60, 255)
+	v.Engine.Renderer.FillRect(v.Bar)
+
+	progress := *v.Bar
+	if v.Replay.Header.Ticks > 0 {
+		progress.W = int32(float64(v.Bar.W) * float64(v.Game.Tick) / float64(v.Replay.Header.Ticks))
+	}
+
+	v.Engine.Renderer.SetDrawColor(224, 128, 26, 255)
+	v.Engine.Renderer.FillRect(&progress)
+
+	// Draw time and speed
+	step := v.Replay.Header.Config.StepLength
+	text := fmt.Sprintf("%s / %s  %dx", formatTime(v.Game.Tick*step, true), formatTime(v.Replay.Header.Ticks*step, true), v.Speed)
+	v.Resource.DrawText(text, v.Bar.X, v.Bar.Y-30, engine.FONT_SMALL)
+
+	// Draw paused text
+	if v.Paused && v.Game.State != GameQuit {
+		v.Game.PausedText.Draw()
+	}
+}
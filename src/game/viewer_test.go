@@ -0,0 +1,61 @@
+// VoV game
+package game
+
+import (
+	"testing"
+
+	"github.com/gen2brain/vov/src/engine"
+)
+
+// Returns viewer of short replay with some thrust
+func newTestViewer(t *testing.T, e *engine.Engine, r *engine.Resource) *Viewer {
+	g := NewGame(e, r)
+	g.SetSeed(5)
+
+	rep := NewReplay(g)
+	rep.RecordMask(0, 0x02, [4]uint8{0, 255, 0, 0})
+	rep.RecordMask(150, 0x04, [4]uint8{0, 0, 200, 0})
+	rep.RecordMask(300, 0x00, [4]uint8{})
+	rep.Header.Ticks = 400
+
+	v := NewViewer(e, r, rep, false)
+	v.OnInit()
+
+	return v
+}
+
+func TestViewerSeek(t *testing.T) {
+	e, r := newTestEngine(t, 1)
+	step := uint32(e.Cfg.StepLength)
+
+	v := newTestViewer(t, e, r)
+	for v.Game.Tick < 350 {
+		e.Advance(step)
+		v.Update()
+	}
+	want := v.Game
+	v.OnQuit()
+
+	// Seek forward, back to start and forward again
+	v = newTestViewer(t, e, r)
+	v.Seek(200)
+	first := v.Game
+
+	v.Seek(100)
+	if v.Game == first || v.Game.Tick != 100 {
+		t.Errorf("backward seek to tick %d, want rebuilt game at 100", v.Game.Tick)
+	}
+
+	v.Seek(350)
+	got := v.Game
+	v.OnQuit()
+
+	if got.Tick != want.Tick || got.Score != want.Score || got.Ship.X != want.Ship.X || got.Ship.Y != want.Ship.Y {
+		t.Errorf("seek tick %d score %d ship %g,%g, want %d %d %g,%g",
+			got.Tick, got.Score, got.Ship.X, got.Ship.Y, want.Tick, want.Score, want.Ship.X, want.Ship.Y)
+	}
+
+	if got.SavedConfig != first.SavedConfig {
+		t.Error("saved config replaced by replay config after seek")
+	}
+}
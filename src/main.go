@@ -3,11 +3,11 @@ package main
 import "C"
 
 import (
-	"math/rand"
+	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
-	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
 
@@ -16,18 +16,50 @@ import (
 )
 
 func run() {
-	// Initialize random number generator
-	rand.Seed(time.Now().UTC().UnixNano())
+	// Command line flags
+	f := &Flags{}
+	if runtime.GOOS != "android" {
+		var err error
+		f, err = ParseFlags(os.Args[1:])
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "ParseFlags: %s\n", err)
+			os.Exit(2)
+		}
+	}
 
 	// Data directory
-	dataDir := ""
-	if runtime.GOOS != "android" {
+	dataDir := f.DataDir
+	if runtime.GOOS != "android" && dataDir == "" {
 		currDir, _ := filepath.Abs(filepath.Dir(os.Args[0]))
 		dataDir = filepath.Join(currDir, "assets")
 	}
 
+	// Config and tuning files
+	engine.ConfigFile = f.Config
+	engine.TuningFile = f.Tuning
+
+	// Load config and apply overrides
+	c := engine.NewConfig()
+	if err := c.SetAll(f.Settings()); err != nil {
+		fmt.Fprintf(os.Stderr, "SetAll: %s\n", err)
+		os.Exit(2)
+	}
+
+	// Values from files are already checked, errors here come from overrides
+	if err := c.Preferences.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Validate: %s\n", err)
+		os.Exit(2)
+	}
+	if err := c.Tuning.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Validate: %s\n", err)
+		os.Exit(2)
+	}
+	c.ResetRuntime()
+
 	// Initialize SDL engine
-	e := engine.NewEngine(engine.NewConfig())
+	e := engine.NewEngine(c)
 	err := e.Init()
 	if err != nil {
 		// Show error message
@@ -43,13 +75,22 @@ func run() {
 		e.SetIcon(r.LoadSurface(engine.ImageIcon))
 	}
 
+	// Set fullscreen
+	if f.Fullscreen {
+		e.Fullscreen()
+	}
+
 	// Set controller
 	e.SetController(r.Mappings)
 
 	// Set accelerometer
 	if runtime.GOOS == "android" {
-		// TODO
-		// e.SetAccelerometer()
+		e.SetAccelerometer()
+	}
+
+	// Watch config files
+	if e.Cfg.WatchConfig {
+		e.Watch()
 	}
 
 	// Set haptic
@@ -72,8 +113,19 @@ func run() {
 	// Load resources
 	r.Load()
 
-	// Change state to menu
-	e.State.Change(game.NewMenu(e, r))
+	// Change state to initial state, state is checked with flags
+	switch f.State {
+	case "game":
+		e.State.Change(game.NewGame(e, r))
+	case "daily":
+		e.State.Change(game.NewDailyGame(e, r))
+	case "options":
+		e.State.Change(game.NewOptions(e, r))
+	case "credits":
+		e.State.Change(game.NewCredits(e, r))
+	default:
+		e.State.Change(game.NewMenu(e, r))
+	}
 
 	// Main loop
 	for e.Running {
@@ -81,18 +133,39 @@ func run() {
 		// Calculate frame start
 		e.StartFrame()
 
+		// Reload changed config files
+		if e.Watcher != nil {
+			e.Watcher.Check()
+		}
+
 		// Handle events
 		e.State.HandleEvents()
 
+		// Update state, once for every simulation step
+		for e.Step() {
+			e.State.Update()
+		}
+
+		// Play music waiting for fade out
+		r.UpdateMusic()
+
 		// Clear screen
 		e.Clear()
 
-		// Update state
-		e.State.Update()
-
 		// Draw
 		e.State.Draw()
 
+		// Record frame for gif
+		e.RecordFrame()
+
+		// Draw config errors
+		r.DrawMessage()
+
+		// Save screenshot
+		if e.TakeScreenshot {
+			e.Screenshot()
+		}
+
 		// Update screen
 		e.Renderer.Present()
 
@@ -0,0 +1,90 @@
+// VoV headless simulation
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gen2brain/vov/src/engine"
+	"github.com/gen2brain/vov/src/game"
+)
+
+func main() {
+	// Default config, user config is not loaded
+	c := &engine.Config{}
+	c.Default()
+
+	dataDir := flag.String("data", "assets", "Data directory")
+	runs := flag.Int("runs", 100, "Number of runs")
+	ticks := flag.Int("ticks", 60*60*50, "Maximum number of ticks per run")
+	delta := flag.Uint("delta", uint(c.StepLength), "Length of tick in milliseconds")
+	width := flag.Int("width", 1024, "Screen width")
+	height := flag.Int("height", 640, "Screen height")
+	seed := flag.Int64("seed", time.Now().UTC().UnixNano(), "Random seed, run n uses seed+n")
+	replay := flag.String("replay", "", "Replay file to verify, runs are ignored")
+	flag.Parse()
+
+	// Initialize headless engine
+	e := engine.NewEngine(c)
+	err := e.InitHeadless(*width, *height)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "InitHeadless: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Load collision surfaces
+	r := engine.NewResource(e, *dataDir)
+	r.SetSeed(*seed)
+	r.Load()
+
+	if *replay != "" {
+		rep, err := game.LoadReplay(*replay)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "LoadReplay: %s\n", err)
+			os.Exit(1)
+		}
+
+		g := game.NewReplayGame(e, r, rep)
+		g.OnInit()
+
+		for g.State != game.GameQuit && g.Tick < *ticks {
+			e.Advance(uint32(c.StepLength))
+			g.Update()
+		}
+
+		g.OnQuit()
+
+		fmt.Printf("replay: score %d (recorded %d), ticks %d (recorded %d)\n", g.Score, rep.Header.Score, g.Tick, rep.Header.Ticks)
+
+		r.Free()
+		e.Destroy()
+		return
+	}
+
+	total := 0
+	for i := 0; i < *runs; i++ {
+		g := game.NewGame(e, r)
+		g.SetSeed(*seed + int64(i))
+		g.OnInit()
+
+		n := 0
+		for ; n < *ticks && g.State != game.GameQuit; n++ {
+			e.Advance(uint32(*delta))
+			g.Update()
+		}
+
+		g.OnQuit()
+
+		total += g.Score
+		fmt.Printf("run %d: seed %d, score %d, ticks %d\n", i+1, g.Seed, g.Score, n)
+	}
+
+	if *runs > 0 {
+		fmt.Printf("average score %d\n", total / *runs)
+	}
+
+	r.Free()
+	e.Destroy()
+}